@@ -50,3 +50,18 @@ app.kubernetes.io/name: {{ include "scheduler-plugins-as-a-second-scheduler.name
 app.kubernetes.io/instance: {{ .Release.Name }}
 {{- end }}
 
+
+{{/*
+Mounts for the fluxion socket and mutual TLS files, shared by the sidecar and scheduler
+*/}}
+{{- define "scheduler-plugins-as-a-second-scheduler.fluxionMounts" -}}
+{{- if .Values.sidecar.socket }}
+- name: fluxion-socket
+  mountPath: /var/run/fluxion
+{{- end }}
+{{- if .Values.sidecar.tlsSecret }}
+- name: fluxion-tls
+  mountPath: /etc/fluxion/tls
+  readOnly: true
+{{- end }}
+{{- end }}
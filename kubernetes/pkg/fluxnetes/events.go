@@ -2,10 +2,13 @@ package fluxnetes
 
 import (
 	"encoding/json"
+	"fmt"
 
 	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
 
 	groups "k8s.io/kubernetes/pkg/scheduler/framework/plugins/fluxnetes/group"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/fluxnetes/labels"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/fluxnetes/state"
 	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/fluxnetes/strategy/workers"
 
 	corev1 "k8s.io/api/core/v1"
@@ -40,6 +43,26 @@ func (q *Queue) UpdatePodEvent(oldObj, newObj interface{}) {
 	default:
 		klog.Infof("Received unknown update event %s for pod %s/%s", newPod.Status.Phase, pod.Status.Phase, pod.Namespace, pod.Name)
 	}
+
+	// Only pods we schedule are in a group with a lifecycle
+	if newPod.Spec.SchedulerName != q.Handle.ProfileName() {
+		return
+	}
+
+	// The first pod of a group to start running means the group is running
+	if newPod.Status.Phase == corev1.PodRunning && pod.Status.Phase != corev1.PodRunning {
+		groupName := groups.GetPodGroupName(newPod)
+		reason := fmt.Sprintf("pod %s is running", newPod.Name)
+		q.transitionGroup(newPod.Namespace, groupName, state.Running, reason)
+	}
+}
+
+// transitionGroup records a group state change, only logging an error
+func (q *Queue) transitionGroup(namespace, groupName string, to state.GroupState, reason string) {
+	err := state.Transition(q.Context, q.Pool, groupName, namespace, to, reason)
+	if err != nil {
+		klog.Errorf("Error recording group %s state: %s", groupName, err)
+	}
 }
 
 // DeletePodEventhandles the delete event handler
@@ -83,16 +106,79 @@ func (q *Queue) DeletePodEvent(podObj interface{}) {
 	fluxID, err := q.GetFluxID(pod.Namespace, groupName)
 
 	// Determine finished status (delete via fluxion flux id ONLY if all are finished)
+	// Pods that were deleted are not listed, so a group with all pods gone is finished
 	finished := true
+	failed := false
 	for _, pod := range pods {
 		isFinished := podutil.IsPodPhaseTerminal(pod.Status.Phase)
 		if !isFinished {
 			finished = false
 			break
 		}
+		if pod.Status.Phase == corev1.PodFailed {
+			failed = true
+		}
+	}
+
+	// An elastic group gives back the node of the pod, unless it has other pods there.
+	// It shrinks and keeps running (and can grow again), so it is not completing.
+	elastic := pod.Labels[labels.PodGroupElasticLabel] == "true"
+	if fluxID > -1 && !finished && elastic {
+		q.shrinkGroup(pod, pods, groupName, fluxID)
+	}
+
+	// Only a group with an allocation (flux id) has a lifecycle to finish
+	if fluxID > -1 {
+		reason := fmt.Sprintf("pod %s deleted", pod.Name)
+		if !finished && elastic {
+			klog.Infof("Elastic group %s keeps running without pod %s", groupName, pod.Name)
+		} else if !finished {
+			q.transitionGroup(pod.Namespace, groupName, state.Completing, reason)
+		} else if failed || pod.Status.Phase == corev1.PodFailed {
+			q.transitionGroup(pod.Namespace, groupName, state.Failed, reason)
+		} else if !podutil.IsPodPhaseTerminal(pod.Status.Phase) {
+
+			// The last pod was deleted before it finished (e.g., kubectl delete job)
+			q.transitionGroup(pod.Namespace, groupName, state.Cancelled, reason+" before it finished")
+		} else {
+			q.transitionGroup(pod.Namespace, groupName, state.Completed, reason)
+		}
 	}
 	if !finished {
 		fluxID = -1
+	} else if fluxID > -1 && klog.V(2).Enabled() {
+		q.logGroupInfo(pod.Namespace, groupName)
 	}
 	err = workers.Cleanup(q.Context, string(podspec), fluxID, false, groupName)
 }
+
+// logGroupInfo logs what fluxion holds for a group (e.g., before it is cancelled),
+// to compare with where its pods ran
+func (q *Queue) logGroupInfo(namespace, groupName string) {
+	info, err := q.GetGroupInfo(namespace, groupName)
+	if err != nil {
+		klog.Errorf("Error getting fluxion info for group %s/%s: %s", namespace, groupName, err)
+		return
+	}
+	klog.Infof("Group %s/%s has %s flux job id %d from %d on nodes %v", namespace, groupName, info.Mode, info.FluxID, info.At, info.Nodes)
+}
+
+// shrinkGroup asks fluxion to release the node of a deleted pod from the allocation
+// of its group, if no other running pod of the group is on the node
+func (q *Queue) shrinkGroup(pod *corev1.Pod, pods []*corev1.Pod, groupName string, fluxID int64) {
+	node := pod.Spec.NodeName
+	if node == "" {
+		return
+	}
+	for _, other := range pods {
+		if other.UID != pod.UID && other.Spec.NodeName == node && !podutil.IsPodPhaseTerminal(other.Status.Phase) {
+			return
+		}
+	}
+	response, err := workers.Shrink(q.Context, fluxID, []string{node})
+	if err != nil {
+		klog.Errorf("Error releasing node %s from group %s (flux job id %d): %s", node, groupName, fluxID, err)
+		return
+	}
+	klog.Infof("Group %s released node %s and has nodes %v", groupName, node, response.Nodes)
+}
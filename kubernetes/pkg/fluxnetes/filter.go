@@ -0,0 +1,67 @@
+package fluxnetes
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	klog "k8s.io/klog/v2"
+
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/fluxnetes/strategy/workers"
+)
+
+// FeasibleNodes runs the in-tree PreFilter and Filter plugins of the profile for a
+// (representative) pod of a group, and returns the nodes that pass. These are the
+// constraints fluxion cannot know about: node selectors and affinity, taints and
+// tolerations, and volume topology. The workers call it (as workers.NodeFilter)
+// each time they ask fluxion for a group, so a node that is added, or that passes
+// later, is checked before it is used.
+//
+// Each node is checked without the pods on it, because fluxion accounts for
+// resources. A plugin that depends on other pods (e.g., inter-pod affinity)
+// does not make sense for a group here, and should stay disabled.
+func (q *Queue) FeasibleNodes(ctx context.Context, pod *corev1.Pod) ([]string, error) {
+	allowed := []string{}
+
+	nodes, err := q.Handle.SharedInformerFactory().Core().V1().Nodes().Lister().List(labels.Everything())
+	if err != nil {
+		return allowed, err
+	}
+
+	state := framework.NewCycleState()
+	result, status, _ := q.Handle.RunPreFilterPlugins(ctx, state, pod)
+	if !status.IsSuccess() {
+		if !status.IsRejected() {
+			return allowed, status.AsError()
+		}
+		// A rejection in PreFilter means no node can work now
+		klog.Infof("[Fluxnetes] Pod %s/%s rejected by PreFilter: %s", pod.Namespace, pod.Name, status.Message())
+		return allowed, nil
+	}
+
+	for _, node := range nodes {
+		if result != nil && !result.AllNodes() && !result.NodeNames.Has(node.Name) {
+			continue
+		}
+		nodeInfo := framework.NewNodeInfo()
+		nodeInfo.SetNode(node)
+		status := q.Handle.RunFilterPlugins(ctx, state, pod, nodeInfo)
+		if status.Code() == framework.Error {
+			return allowed, fmt.Errorf("filter for node %s: %w", node.Name, status.AsError())
+		}
+		if status.IsSuccess() {
+			allowed = append(allowed, node.Name)
+		} else {
+			klog.V(4).Infof("[Fluxnetes] Node %s filtered for pod %s/%s: %s", node.Name, pod.Namespace, pod.Name, status.Message())
+		}
+	}
+	return allowed, nil
+}
+
+// setupFilter has the workers run the filter plugins for a group each time they
+// ask fluxion for it
+func (q *Queue) setupFilter() {
+	workers.NodeFilter = q.FeasibleNodes
+}
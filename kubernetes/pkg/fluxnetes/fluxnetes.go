@@ -39,6 +39,7 @@ type JobResult struct {
 	PodID     string `json:"podid"`
 	PodSpec   string `json:"podspec"`
 	Names     string `json:"names"`
+	UIDs      string `json:"uids"`
 	Namespace string `json:"namespace"`
 	GroupName string `json:"groupName"`
 }
@@ -51,6 +52,10 @@ func (j JobResult) GetPodNames() []string {
 	return strings.Split(j.Names, ",")
 }
 
+func (j JobResult) GetPodUIDs() []string {
+	return strings.Split(j.UIDs, ",")
+}
+
 // Fluxnetes (as a plugin) is only enabled for the queue sort
 type Fluxnetes struct{}
 
@@ -10,6 +10,21 @@ const (
 	// We use the same label to be consistent
 	PodGroupLabel     = "fluxnetes.group-name"
 	PodGroupSizeLabel = "fluxnetes.group-size"
+
+	// A topology level (e.g., zone or rack) to pack the group into one domain
+	// of, or to spread the group evenly across domains of
+	PodGroupPackLabel   = "fluxnetes.group-pack"
+	PodGroupSpreadLabel = "fluxnetes.group-spread"
+
+	// Set to "true" for a group to have whole nodes, with one pod on each
+	PodGroupExclusiveLabel = "fluxnetes.exclusive"
+
+	// The fluxion match policy (e.g., low, high, lonode or locality) for a group
+	PodGroupPolicyLabel = "fluxnetes.match-policy"
+
+	// Set to "true" for a running group to take more pods, and give back
+	// nodes as its pods are deleted
+	PodGroupElasticLabel = "fluxnetes.elastic"
 )
 
 // GetPodGroupLabel get pod group name from pod labels
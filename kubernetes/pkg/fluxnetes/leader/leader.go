@@ -0,0 +1,132 @@
+package leader
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	klog "k8s.io/klog/v2"
+
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/fluxnetes/queries"
+)
+
+const (
+	// LockKey is the advisory lock shared by all Fluxnetes replicas using the same database
+	LockKey int64 = 4242
+
+	// How often a standby tries to take the lock, and the leader checks that it still has it
+	RetryPeriod = 5 * time.Second
+)
+
+// Lock is a Postgres session advisory lock. Only one replica can hold it at once,
+// and that replica (the leader) is the only one that runs the queue: moving groups
+// from provisional to pending, working jobs (asking fluxion) and binding pods.
+// The lock belongs to a single dedicated connection, so if the leader dies or
+// loses the database, postgres releases it and a standby can take over.
+type Lock struct {
+	pool *pgxpool.Pool
+	conn *pgxpool.Conn
+	key  int64
+
+	// A pgx connection is not safe for concurrent use
+	mutex sync.Mutex
+}
+
+// NewLock returns a lock for the key, not yet acquired
+func NewLock(pool *pgxpool.Pool, key int64) *Lock {
+	return &Lock{pool: pool, key: key}
+}
+
+// Acquire blocks until the lock is held, or the context is done
+func (l *Lock) Acquire(ctx context.Context) error {
+	waiting := false
+	for {
+		acquired, err := l.tryAcquire(ctx)
+		if err != nil {
+			klog.Errorf("[Fluxnetes] Error trying to acquire leader lock: %s", err)
+		}
+		if acquired {
+			klog.Infof("[Fluxnetes] Acquired leader lock %d, this replica is the leader", l.key)
+			return nil
+		}
+		if !waiting {
+			klog.Infof("[Fluxnetes] Leader lock %d is held by another replica, waiting as standby", l.key)
+			waiting = true
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(RetryPeriod):
+		}
+	}
+}
+
+// tryAcquire makes one attempt to take the lock on a dedicated connection
+func (l *Lock) tryAcquire(ctx context.Context) (bool, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.conn == nil {
+		conn, err := l.pool.Acquire(ctx)
+		if err != nil {
+			return false, err
+		}
+		l.conn = conn
+	}
+	acquired := false
+	err := l.conn.QueryRow(ctx, queries.TryAdvisoryLockQuery, l.key).Scan(&acquired)
+	if err != nil {
+		// The connection is likely broken, so we start with a new one next time
+		l.conn.Release()
+		l.conn = nil
+		return false, err
+	}
+	return acquired, nil
+}
+
+// Held returns an error if the lock is no longer held. The lock lives and dies
+// with the connection, so a connection that cannot be reached has lost it.
+func (l *Lock) Held(ctx context.Context) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.conn == nil {
+		return fmt.Errorf("leader lock %d is not held", l.key)
+	}
+	err := l.conn.Ping(ctx)
+	if err != nil {
+		return fmt.Errorf("leader lock %d connection lost: %w", l.key, err)
+	}
+	return nil
+}
+
+// Watch checks the lock until the context is done, and calls lost if it goes away
+func (l *Lock) Watch(ctx context.Context, lost func(error)) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(RetryPeriod):
+			err := l.Held(ctx)
+			if err != nil && ctx.Err() == nil {
+				lost(err)
+				return
+			}
+		}
+	}
+}
+
+// Release gives up the lock, so a standby can take over right away
+func (l *Lock) Release(ctx context.Context) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.conn == nil {
+		return nil
+	}
+	defer func() {
+		l.conn.Release()
+		l.conn = nil
+	}()
+	_, err := l.conn.Exec(ctx, queries.AdvisoryUnlockQuery, l.key)
+	return err
+}
@@ -12,9 +12,9 @@ const (
 	DeleteReservationsQuery = "truncate reservations; delete from reservations;"
 	GetReservationsQuery    = "select (group_name, flux_id) from reservations;"
 
-	// We need to get a single podspec for binding, etc
-	GetPodspecQuery = "select podspec from pods_provisional where group_name = $1 and name = $2 and namespace = $3;"
-	GetPodsQuery    = "select name, podspec from pods_provisional where group_name = $1 and namespace = $2;"
+	// We need to get a single podspec for binding, etc. Pods are matched by uid and not name
+	GetPodspecQuery = "select podspec from pods_provisional where group_name = $1 and uid = $2 and namespace = $3;"
+	GetPodsQuery    = "select name, uid, podspec from pods_provisional where group_name = $1 and namespace = $2;"
 
 	// This query should achieve the following
 	// 1. Select groups for which the size >= the number of pods we've seen
@@ -23,7 +23,7 @@ const (
 	SelectGroupsAtSizeQuery = "select group_name, group_size, duration, podspec, namespace from groups_provisional where current_size >= group_size order by created_at desc;"
 
 	// This currently will use one podspec (and all names) and we eventually want it to use all podspecs
-	SelectPodsQuery = `select name, podspec from pods_provisional where group_name = $1 and namespace = $2;`
+	SelectPodsQuery = `select name, uid, podspec from pods_provisional where group_name = $1 and namespace = $2;`
 
 	// Pending queue - inserted after moving from provisional
 	InsertIntoPending = "insert into pending_queue (group_name, namespace, group_size) SELECT '%s', '%s', '%d' WHERE NOT EXISTS (SELECT (group_name, namespace) FROM pending_queue WHERE group_name = '%s' and namespace = '%s');"
@@ -33,7 +33,10 @@ const (
 	DeleteProvisionalPodsQuery   = "delete from pods_provisional where group_name = $1 and namespace = $2;"
 
 	// TODO add created_at back
-	InsertIntoProvisionalQuery = "insert into pods_provisional (podspec, namespace, name, duration, group_name, created_at) select '%s', '%s', '%s', %d, '%s', $1 where not exists (select (group_name, name, namespace) from pods_provisional where group_name = '%s' and namespace = '%s' and name = '%s');"
+	InsertIntoProvisionalQuery = "insert into pods_provisional (podspec, namespace, name, uid, duration, group_name, created_at) select '%s', '%s', '%s', '%s', %d, '%s', $1 where not exists (select (group_name, uid, namespace) from pods_provisional where group_name = '%s' and namespace = '%s' and uid = '%s');"
+
+	// A pod that was deleted and recreated with the same name has a new uid, and the old entry is stale
+	DeleteStaleProvisionalPodQuery = "delete from pods_provisional where group_name = $1 and namespace = $2 and name = $3 and uid != $4;"
 
 	// Enqueue queries
 	// TODO these need escaping (sql injection)
@@ -42,6 +45,7 @@ const (
 	// Note that we add a current_size of 1 here assuming the first creation is done paired with an existing pod (and then don't need to increment again)
 	InsertIntoGroupProvisional = "insert into groups_provisional (group_name, namespace, group_size, duration, podspec, current_size, created_at) select '%s', '%s', '%d', '%d', '%s', '1', $1 WHERE NOT EXISTS (SELECT (group_name, namespace) FROM groups_provisional WHERE group_name = '%s' and namespace = '%s');"
 	IncrementGroupProvisional  = "update groups_provisional set current_size = current_size + 1 where group_name = '%s' and namespace = '%s';"
+	DecrementGroupProvisional  = "update groups_provisional set current_size = current_size - $1 where group_name = $2 and namespace = $3;"
 
 	// After allocate success, we update pending with the ID. We retrieve it to issue fluxion to cancel when it finishes
 	UpdatingPendingWithFluxID = "update pending_queue set flux_id = $1 where group_name = $2 and namespace = $3;"
@@ -53,4 +57,20 @@ const (
 
 	// We remove from pending to allow another group submission of the same name on cleanup
 	DeleteFromPendingQuery = "delete from pending_queue where group_name=$1 and namespace=$2;"
+
+	// Group lifecycle state. A group that starts again (provisional) gets a new group id,
+	// and every transition is recorded in the history table
+	GetGroupStateQuery       = "select group_id, state from group_state where group_name = $1 and namespace = $2 for update;"
+	GetGroupStateNoLockQuery = "select group_id, state from group_state where group_name = $1 and namespace = $2;"
+	InsertGroupStateQuery    = "insert into group_state (group_id, group_name, namespace, state, updated_at) values (nextval('group_id_seq'), $1, $2, $3, NOW()) on conflict (group_name, namespace) do update set group_id = excluded.group_id, state = excluded.state, updated_at = excluded.updated_at returning group_id;"
+	UpdateGroupStateQuery    = "update group_state set state = $1, updated_at = NOW() where group_name = $2 and namespace = $3;"
+	InsertGroupHistoryQuery  = "insert into group_state_history (group_id, group_name, namespace, from_state, to_state, reason) values ($1, $2, $3, $4, $5, $6);"
+
+	// Leader election across scheduler replicas uses a session advisory lock. It is held as long as
+	// the connection that took it is open, and released by postgres when the replica goes away.
+	TryAdvisoryLockQuery = "select pg_try_advisory_lock($1);"
+	AdvisoryUnlockQuery  = "select pg_advisory_unlock($1);"
+
+	// Readiness checks that the tables we use exist, and returns the ones that do not
+	MissingTablesQuery = "select name from unnest($1::text[]) as name where to_regclass(name) is null;"
 )
@@ -22,9 +22,14 @@ import (
 	"k8s.io/client-go/tools/cache"
 
 	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/fluxnetes/defaults"
+	pb "k8s.io/kubernetes/pkg/scheduler/framework/plugins/fluxnetes/fluxion-grpc"
 	groups "k8s.io/kubernetes/pkg/scheduler/framework/plugins/fluxnetes/group"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/fluxnetes/leader"
 	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/fluxnetes/queries"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/fluxnetes/state"
 	strategies "k8s.io/kubernetes/pkg/scheduler/framework/plugins/fluxnetes/strategy"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/fluxnetes/strategy/workers"
 	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/fluxnetes/types"
 )
 
@@ -39,7 +44,10 @@ type Queue struct {
 	riverClient  *river.Client[pgx.Tx]
 	EventChannel *QueueEvent
 	Strategy     strategies.QueueStrategy
-	Handle       framework.Handle
+	Handle       framework.Framework
+
+	// Only the replica holding the leader lock runs the queue
+	Leader *leader.Lock
 
 	// IMPORTANT: subscriptions need to use same context
 	// that client submit them uses
@@ -62,7 +70,10 @@ type QueueEvent struct {
 }
 
 // NewQueue starts a new queue with a river client
-func NewQueue(ctx context.Context, handle framework.Handle) (*Queue, error) {
+// When more than one replica shares the database, this blocks until this
+// replica holds the leader lock, so only one replica submits groups,
+// works jobs, and binds pods.
+func NewQueue(ctx context.Context, handle framework.Framework) (*Queue, error) {
 	pool, err := pgxpool.New(ctx, os.Getenv("DATABASE_URL"))
 	if err != nil {
 		return nil, err
@@ -93,6 +104,20 @@ func NewQueue(ctx context.Context, handle framework.Handle) (*Queue, error) {
 		return nil, err
 	}
 
+	// Standby replicas wait here. The river client is not started until we are
+	// the leader, so a standby does not work jobs or receive their events.
+	lock := leader.NewLock(pool, leader.LockKey)
+	err = lock.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// If the lock is lost another replica can become leader. Like the kube-scheduler
+	// when it loses its lease, we exit so we cannot double-submit or double-bind.
+	go lock.Watch(ctx, func(err error) {
+		klog.Fatalf("[Fluxnetes] Lost leader lock, exiting: %s", err)
+	})
+
 	// Create the queue and setup events for it
 	err = riverClient.Start(ctx)
 	if err != nil {
@@ -113,8 +138,10 @@ func NewQueue(ctx context.Context, handle framework.Handle) (*Queue, error) {
 		Context:          ctx,
 		ReservationDepth: depth,
 		Handle:           handle,
+		Leader:           lock,
 	}
 	queue.setupEvents()
+	queue.setupFilter()
 	return &queue, nil
 }
 
@@ -122,7 +149,14 @@ func NewQueue(ctx context.Context, handle framework.Handle) (*Queue, error) {
 // issue stop, so we can leave out workers and queue from Config
 func (q *Queue) Stop(ctx context.Context) error {
 	if q.riverClient != nil {
-		return q.riverClient.Stop(ctx)
+		err := q.riverClient.Stop(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	// Give up the lock so a standby can take over right away
+	if q.Leader != nil {
+		return q.Leader.Release(ctx)
 	}
 	return nil
 }
@@ -168,6 +202,19 @@ func (q *Queue) GetFluxID(namespace, groupName string) (int64, error) {
 	return int64(fluxID), err
 }
 
+// GetGroupInfo returns fluxion's view (reserved or allocated, start time and resources)
+// of a group in the pending queue. A group without a flux id has not been matched yet.
+func (q *Queue) GetGroupInfo(namespace, groupName string) (*pb.InfoResponse, error) {
+	fluxID, err := q.GetFluxID(namespace, groupName)
+	if err != nil {
+		return nil, err
+	}
+	if fluxID < 0 {
+		return nil, fmt.Errorf("group %s/%s does not have a flux job id", namespace, groupName)
+	}
+	return workers.Info(q.Context, fluxID)
+}
+
 // Get all pods in a group
 func (q *Queue) GetGroupPods(namespace, groupName string) ([]*corev1.Pod, error) {
 	podlist := []*corev1.Pod{}
@@ -201,10 +248,16 @@ func (q *Queue) GetGroupPods(namespace, groupName string) ([]*corev1.Pod, error)
 
 	// Assemble one podspec, and list of pods that we will need
 	for _, item := range pods {
-		// Get the live pod with the API, ignore not found
+		// Get the live pod with the API. A pod that is not found was deleted, and is left out
 		pod, err := clientset.CoreV1().Pods(namespace).Get(q.Context, item.Name, metav1.GetOptions{})
-		if err != nil && !errors.IsNotFound(err) {
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
 			klog.Infof("Error retrieving Pod %s/%s: %s", namespace, item.Name, err)
+		} else if string(pod.UID) != item.UID {
+			// A pod with the same name was recreated, and it is not a member of this group
+			klog.Infof("Pod %s/%s has uid %s and not group member uid %s", namespace, item.Name, pod.UID, item.UID)
 		} else {
 			podlist = append(podlist, pod)
 		}
@@ -216,7 +269,8 @@ func (q *Queue) GetGroupPods(namespace, groupName string) ([]*corev1.Pod, error)
 // We need to be able to do this to complete a scheduling cycle
 // This podSpec will eventually need to go into the full request to
 // ask fluxion for nodes, right now we still use a single representative one
-func (q *Queue) GetPodSpec(namespace, name, groupName string) (*corev1.Pod, error) {
+// The pod is looked up by uid, since the name can belong to a recreated pod.
+func (q *Queue) GetPodSpec(namespace, uid, groupName string) (*corev1.Pod, error) {
 
 	pool, err := pgxpool.New(context.Background(), os.Getenv("DATABASE_URL"))
 	if err != nil {
@@ -226,10 +280,10 @@ func (q *Queue) GetPodSpec(namespace, name, groupName string) (*corev1.Pod, erro
 	defer pool.Close()
 
 	var podspec string
-	result := pool.QueryRow(context.Background(), queries.GetPodspecQuery, groupName, name, namespace)
+	result := pool.QueryRow(context.Background(), queries.GetPodspecQuery, groupName, uid, namespace)
 	err = result.Scan(&podspec)
 	if err != nil {
-		klog.Infof("Error scanning podspec for %s/%s", namespace, name)
+		klog.Infof("Error scanning podspec for %s with uid %s", namespace, uid)
 		return nil, err
 	}
 	var pod corev1.Pod
@@ -237,6 +291,24 @@ func (q *Queue) GetPodSpec(namespace, name, groupName string) (*corev1.Pod, erro
 	return &pod, err
 }
 
+// CheckLivePod ensures the pod we are about to bind is still the pod in the cluster.
+// A pod deleted and recreated with the same name has a new uid, and the saved
+// podspec no longer describes it.
+func (q *Queue) CheckLivePod(pod *corev1.Pod) error {
+	livePod, err := q.Handle.SharedInformerFactory().Core().V1().Pods().Lister().Pods(pod.Namespace).Get(pod.Name)
+	if err != nil {
+		return err
+	}
+	if livePod.UID != pod.UID {
+		return fmt.Errorf("pod %s/%s has uid %s, but the group member has uid %s", pod.Namespace, pod.Name, livePod.UID, pod.UID)
+	}
+	// A previous leader may have bound the pod before a job was worked again
+	if livePod.Spec.NodeName != "" {
+		return fmt.Errorf("pod %s/%s is already bound to node %s", pod.Namespace, pod.Name, livePod.Spec.NodeName)
+	}
+	return nil
+}
+
 // GetInformer returns the pod informer to run as a go routine
 func (q *Queue) GetInformer() cache.SharedIndexInformer {
 
@@ -294,6 +366,12 @@ func (q *Queue) Enqueue(pod *corev1.Pod) (types.EnqueueStatus, error) {
 // This mimics what Kubernetes does. Note that jobs can be sorted
 // based on the scheduled at time AND priority.
 func (q *Queue) Schedule() error {
+	// Only the leader moves groups, otherwise two replicas could submit the same group
+	err := q.Leader.Held(q.Context)
+	if err != nil {
+		return err
+	}
+
 	// Queue Strategy "Schedule" moves provisional to the worker queue
 	// We get them back in a back to schedule
 
@@ -303,6 +381,20 @@ func (q *Queue) Schedule() error {
 	}
 
 	if len(batch) > 0 {
+
+		// Record that each group was submit before the jobs are inserted, since
+		// a worker can allocate (or reserve) a group as soon as its job is there
+		for _, params := range batch {
+			jobArgs, ok := params.Args.(workers.JobArgs)
+			if !ok {
+				continue
+			}
+			err := state.Transition(q.Context, q.Pool, jobArgs.GroupName, jobArgs.Namespace, state.Submitted, "submit to worker queue")
+			if err != nil {
+				klog.Errorf("Error recording group %s state: %s", jobArgs.GroupName, err)
+			}
+		}
+
 		count, err := q.riverClient.InsertMany(q.Context, batch)
 		if err != nil {
 			return err
@@ -314,6 +406,45 @@ func (q *Queue) Schedule() error {
 	return q.Strategy.PostSubmit(q.Context, q.Pool, q.riverClient)
 }
 
+// Grow submits a pod of an elastic group that is already allocated to the grow
+// worker, which asks fluxion to add it to the allocation. The pod is bound when
+// the job completes, like the pods of a group.
+func (q *Queue) Grow(pod *corev1.Pod) error {
+	groupName := groups.GetPodGroupName(pod)
+	fluxID, err := q.GetFluxID(pod.Namespace, groupName)
+	if err != nil {
+		return err
+	}
+	duration, err := groups.GetPodGroupDuration(pod)
+	if err != nil {
+		return err
+	}
+	podspec, err := json.Marshal(pod)
+	if err != nil {
+		return err
+	}
+	args := workers.GrowArgs{
+		Podspec:   string(podspec),
+		GroupName: groupName,
+		Namespace: pod.Namespace,
+		Duration:  int32(duration),
+		FluxID:    fluxID,
+		Names:     pod.Name,
+		UIDs:      string(pod.UID),
+	}
+	insertOpts := river.InsertOpts{
+		MaxAttempts: defaults.MaxAttempts,
+		Tags:        []string{q.Strategy.Name()},
+		Queue:       river.QueueDefault,
+	}
+	_, err = q.riverClient.Insert(q.Context, args, &insertOpts)
+	if err != nil {
+		return err
+	}
+	klog.Infof("[Fluxnetes] Grow group %s (flux job id %d) with pod %s/%s", groupName, fluxID, pod.Namespace, pod.Name)
+	return nil
+}
+
 // GetCreationTimestamp returns the creation time of a podGroup or a pod in seconds (time.MicroTime)
 // We either get this from the pod itself (if size 1) or from the database
 func (q *Queue) GetCreationTimestamp(pod *corev1.Pod, groupName string) (metav1.MicroTime, error) {
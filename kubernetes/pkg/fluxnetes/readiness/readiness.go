@@ -0,0 +1,214 @@
+package readiness
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivermigrate"
+	klog "k8s.io/klog/v2"
+
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/fluxnetes/queries"
+)
+
+const (
+	// DefaultAddress is where readiness is served, if FLUXNETES_READY_ADDRESS is not set
+	DefaultAddress = ":10260"
+
+	// The first wait after a check fails, which doubles up to the longest
+	initialBackoff = time.Second
+	maxBackoff     = 30 * time.Second
+
+	// How often a check that passed is run again
+	RecheckPeriod = 10 * time.Second
+
+	// The longest one check can take
+	checkTimeout = 10 * time.Second
+)
+
+// Tables the scheduler uses, which are made when postgres is first started
+var RequiredTables = []string{
+	"pods_provisional",
+	"groups_provisional",
+	"reservations",
+	"pending_queue",
+	"group_state",
+	"group_state_history",
+}
+
+// Check returns an error if a dependency cannot be used
+type Check func(ctx context.Context) error
+
+// Status is the result of each check. The scheduler is ready when all of the
+// checks have passed, and stops being ready when one of them fails again.
+type Status struct {
+	mutex  sync.Mutex
+	checks map[string]error
+}
+
+// NewStatus returns a status with checks that have not passed yet
+func NewStatus(names ...string) *Status {
+	status := &Status{checks: map[string]error{}}
+	for _, name := range names {
+		status.checks[name] = fmt.Errorf("%s has not been checked", name)
+	}
+	return status
+}
+
+func (s *Status) set(name string, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.checks[name] = err
+}
+
+// Ready returns nil if all checks passed, and otherwise why they did not
+func (s *Status) Ready() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	names := []string{}
+	for name := range s.checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	failed := []error{}
+	for _, name := range names {
+		if err := s.checks[name]; err != nil {
+			failed = append(failed, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return errors.Join(failed...)
+}
+
+// ServeHTTP answers the readiness probe
+func (s *Status) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	err := s.Ready()
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprintln(w, err)
+		return
+	}
+	fmt.Fprintln(w, "ok")
+}
+
+// Serve serves readiness at /readyz until the context is done. The address is
+// from FLUXNETES_READY_ADDRESS, and defaults to DefaultAddress.
+func (s *Status) Serve(ctx context.Context) {
+	address := os.Getenv("FLUXNETES_READY_ADDRESS")
+	if address == "" {
+		address = DefaultAddress
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/readyz", s)
+	server := &http.Server{Addr: address, Handler: mux, ReadHeaderTimeout: checkTimeout}
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+	klog.Infof("[Fluxnetes] Serving readiness at %s/readyz", address)
+	err := server.ListenAndServe()
+	if err != nil && err != http.ErrServerClosed {
+		klog.Errorf("[Fluxnetes] Error serving readiness: %s", err)
+	}
+}
+
+// run runs a check once, with a timeout, and saves the result
+func (s *Status) run(ctx context.Context, name string, check Check) error {
+	checkCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+	err := check(checkCtx)
+	s.set(name, err)
+	return err
+}
+
+// Wait blocks until a check passes, trying again with backoff, or the context is done
+func (s *Status) Wait(ctx context.Context, name string, check Check) error {
+	backoff := initialBackoff
+	for {
+		err := s.run(ctx, name, check)
+		if err == nil {
+			klog.Infof("[Fluxnetes] %s is ready", name)
+			return nil
+		}
+		klog.Infof("[Fluxnetes] Waiting %s for %s: %s", backoff, name, err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, maxBackoff)
+	}
+}
+
+// Watch runs a check that passed again every RecheckPeriod until the context is done
+func (s *Status) Watch(ctx context.Context, name string, check Check) {
+	ticker := time.NewTicker(RecheckPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			previous := s.checkError(name)
+			err := s.run(ctx, name, check)
+			if err != nil && previous == nil {
+				klog.Errorf("[Fluxnetes] %s is not ready: %s", name, err)
+			} else if err == nil && previous != nil {
+				klog.Infof("[Fluxnetes] %s is ready again", name)
+			}
+		}
+	}
+}
+
+func (s *Status) checkError(name string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.checks[name]
+}
+
+// CheckDatabase checks that we can connect to postgres, that the tables we use
+// exist, and that the river migrations have been run
+func CheckDatabase(ctx context.Context) error {
+	pool, err := pgxpool.New(ctx, os.Getenv("DATABASE_URL"))
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+	err = pool.Ping(ctx)
+	if err != nil {
+		return err
+	}
+
+	rows, err := pool.Query(ctx, queries.MissingTablesQuery, RequiredTables)
+	if err != nil {
+		return err
+	}
+	missing, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing tables %s", strings.Join(missing, ", "))
+	}
+
+	migrator, err := rivermigrate.New(riverpgxv5.New(pool), nil)
+	if err != nil {
+		return err
+	}
+	result, err := migrator.Validate(ctx)
+	if err != nil {
+		return err
+	}
+	if !result.OK {
+		return fmt.Errorf("river migrations have not been run: %s", strings.Join(result.Messages, "; "))
+	}
+	return nil
+}
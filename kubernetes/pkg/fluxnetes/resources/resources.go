@@ -5,7 +5,9 @@ import (
 
 	v1 "k8s.io/api/core/v1"
 	klog "k8s.io/klog/v2"
+	v1helper "k8s.io/kubernetes/pkg/apis/core/v1/helper"
 	pb "k8s.io/kubernetes/pkg/scheduler/framework/plugins/fluxnetes/fluxion-grpc"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/fluxnetes/labels"
 )
 
 // TODO delete this function to be replaced by one above
@@ -43,8 +45,10 @@ func PreparePodJobSpec(pod *v1.Pod, groupName string) *pb.PodSpec {
 	// GPU cannot be shared across containers, but we
 	// take a count for the pod for the PodSpec
 	var cpus int32 = 0
+	var millicpus int64 = 0
 	var memory int64 = 0
 	var gpus int64 = 0
+	extended := map[string]int64{}
 
 	// I think we are OK to sum this too
 	// https://github.com/kubernetes/kubectl/blob/master/pkg/describe/describe.go#L4211-L4213
@@ -56,6 +60,7 @@ func PreparePodJobSpec(pod *v1.Pod, groupName string) *pb.PodSpec {
 		// This is a limited set of resources owned by the pod
 		specRequests := container.Resources.Requests
 		cpus += int32(specRequests.Cpu().Value())
+		millicpus += specRequests.Cpu().MilliValue()
 		memory += specRequests.Memory().Value()
 		storage += specRequests.StorageEphemeral().Value()
 
@@ -63,6 +68,10 @@ func PreparePodJobSpec(pod *v1.Pod, groupName string) *pb.PodSpec {
 		gpuSpec := specLimits["nvidia.com/gpu"]
 		gpus += gpuSpec.Value()
 
+		// Extended resources (devices, hugepages) are matched by name in the graph
+		for name, quantity := range extendedResources(container.Resources) {
+			extended[name] += quantity
+		}
 	}
 
 	// If we have zero cpus, assume 1
@@ -71,11 +80,61 @@ func PreparePodJobSpec(pod *v1.Pod, groupName string) *pb.PodSpec {
 		cpus = 1
 	}
 	podSpec.Cpu = cpus
+	podSpec.MilliCpu = millicpus
 	podSpec.Gpu = gpus
 	podSpec.Memory = memory
 	podSpec.Storage = storage
+	podSpec.Resources = extended
+
+	// Fluxion matches these against node labels in the graph
+	podSpec.NodeSelector = pod.Spec.NodeSelector
+	podSpec.NodeAffinity = getNodeAffinity(pod)
 
-	// I removed specRequests.Cpu().MilliValue() but we can add back some derivative if desired
-	klog.Infof("[Jobspec] Pod spec: CPU %v, memory %v, GPU %v, storage %v", podSpec.Cpu, podSpec.Memory, podSpec.Gpu, podSpec.Storage)
+	// Where the group goes in the topology
+	podSpec.Pack = pod.Labels[labels.PodGroupPackLabel]
+	podSpec.Spread = pod.Labels[labels.PodGroupSpreadLabel]
+	podSpec.Exclusive = pod.Labels[labels.PodGroupExclusiveLabel] == "true"
+	podSpec.Policy = pod.Labels[labels.PodGroupPolicyLabel]
+
+	klog.Infof("[Jobspec] Pod spec: CPU %v (%vm), memory %v, GPU %v, storage %v, extended %v", podSpec.Cpu, podSpec.MilliCpu, podSpec.Memory, podSpec.Gpu, podSpec.Storage, podSpec.Resources)
 	return podSpec
 }
+
+// extendedResources returns the extended resources (e.g., nvidia.com/gpu or
+// hugepages-2Mi) of a container. A request that is not set defaults to the limit.
+func extendedResources(requirements v1.ResourceRequirements) map[string]int64 {
+	resources := map[string]int64{}
+	for name, quantity := range requirements.Limits {
+		if v1helper.IsExtendedResourceName(name) || v1helper.IsHugePageResourceName(name) {
+			resources[string(name)] = quantity.Value()
+		}
+	}
+	for name, quantity := range requirements.Requests {
+		if v1helper.IsExtendedResourceName(name) || v1helper.IsHugePageResourceName(name) {
+			resources[string(name)] = quantity.Value()
+		}
+	}
+	return resources
+}
+
+// getNodeAffinity returns the required node affinity terms of a pod.
+// Preferred terms are not included, since fluxion does not score nodes.
+func getNodeAffinity(pod *v1.Pod) []*pb.NodeSelectorTerm {
+	terms := []*pb.NodeSelectorTerm{}
+	affinity := pod.Spec.Affinity
+	if affinity == nil || affinity.NodeAffinity == nil || affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return terms
+	}
+	for _, term := range affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		requirements := []*pb.NodeSelectorRequirement{}
+		for _, expression := range term.MatchExpressions {
+			requirements = append(requirements, &pb.NodeSelectorRequirement{
+				Key:      expression.Key,
+				Operator: string(expression.Operator),
+				Values:   expression.Values,
+			})
+		}
+		terms = append(terms, &pb.NodeSelectorTerm{Requirements: requirements})
+	}
+	return terms
+}
@@ -0,0 +1,157 @@
+package state
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	klog "k8s.io/klog/v2"
+
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/fluxnetes/queries"
+)
+
+// GroupState is the lifecycle state of a group. Each transition is validated
+// and written to the group_state_history table with a timestamp and reason,
+// so we can derive wait time, time to allocation and run time per group.
+type GroupState string
+
+const (
+	// The group is seen, but we don't have all pods yet
+	Provisional GroupState = "provisional"
+
+	// All pods are seen, and the group was moved to pending
+	Ready GroupState = "ready"
+
+	// The group was submit as a job to the worker queue
+	Submitted GroupState = "submitted"
+
+	// Fluxion could not allocate, but gave a reservation
+	Reserved GroupState = "reserved"
+
+	// Fluxion allocated nodes and the pods can be bound
+	Allocated GroupState = "allocated"
+
+	// At least one pod in the group is running
+	Running GroupState = "running"
+
+	// Some (but not all) pods in the group have finished
+	Completing GroupState = "completing"
+
+	// Terminal states. A new group with the same name starts again at provisional.
+	Completed GroupState = "completed"
+	Failed    GroupState = "failed"
+	Cancelled GroupState = "cancelled"
+)
+
+// transitions are the valid next states for each state. Any state can also go
+// back to provisional (see ValidateTransition).
+var transitions = map[GroupState][]GroupState{
+	Provisional: {Ready, Failed, Cancelled},
+	Ready:       {Submitted, Failed, Cancelled},
+	Submitted:   {Reserved, Allocated, Failed, Cancelled},
+	Reserved:    {Submitted, Allocated, Failed, Cancelled},
+	Allocated:   {Running, Completing, Completed, Failed, Cancelled},
+	Running:     {Completing, Completed, Failed, Cancelled},
+	Completing:  {Completed, Failed, Cancelled},
+	Completed:   {Provisional},
+	Failed:      {Provisional},
+	Cancelled:   {Provisional},
+}
+
+// isKnown returns true if the state is one of ours
+func isKnown(s GroupState) bool {
+	_, ok := transitions[s]
+	return ok
+}
+
+// IsTerminal returns true if the group is done
+func (s GroupState) IsTerminal() bool {
+	return s == Completed || s == Failed || s == Cancelled
+}
+
+// ValidateTransition returns an error if we cannot go from one state to the other
+// An empty from state means the group is new, and can only start as provisional.
+// A group that is not done can also start again at provisional, as a reset: it can
+// be deleted and made again before it gets a flux id (e.g., while ready, submitted
+// or reserved), and then nothing moves it to a terminal state.
+func ValidateTransition(from, to GroupState) error {
+	if from == "" {
+		if to != Provisional {
+			return fmt.Errorf("a new group must start as %s, not %s", Provisional, to)
+		}
+		return nil
+	}
+	if to == Provisional && isKnown(from) {
+		return nil
+	}
+	for _, next := range transitions[from] {
+		if next == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid group state transition from %s to %s", from, to)
+}
+
+// Transition moves a group to a new state, recording the change in the history table.
+// Transitioning to the current state is a no-op, since many events (e.g., pods
+// in the same group starting) can report the same state.
+func Transition(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	groupName, namespace string,
+	to GroupState,
+	reason string,
+) error {
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock the current state (if the group is known) so transitions are serialized
+	var groupID int64
+	var from GroupState
+	row := tx.QueryRow(ctx, queries.GetGroupStateQuery, groupName, namespace)
+	err = row.Scan(&groupID, &from)
+	if err != nil && err != pgx.ErrNoRows {
+		return err
+	}
+	if from == to {
+		return nil
+	}
+	err = ValidateTransition(from, to)
+	if err != nil {
+		return fmt.Errorf("group %s/%s: %w", namespace, groupName, err)
+	}
+
+	// Starting as provisional is a new lifecycle for the group, and gets a new id
+	if to == Provisional {
+		row = tx.QueryRow(ctx, queries.InsertGroupStateQuery, groupName, namespace, string(to))
+		err = row.Scan(&groupID)
+	} else {
+		_, err = tx.Exec(ctx, queries.UpdateGroupStateQuery, string(to), groupName, namespace)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, queries.InsertGroupHistoryQuery, groupID, groupName, namespace, string(from), string(to), reason)
+	if err != nil {
+		return err
+	}
+	klog.Infof("[Fluxnetes] Group %s/%s transitioned from '%s' to '%s': %s", namespace, groupName, from, to, reason)
+	return tx.Commit(ctx)
+}
+
+// GetState returns the current state of a group, and empty if it is not known
+func GetState(ctx context.Context, pool *pgxpool.Pool, groupName, namespace string) (GroupState, error) {
+	var groupID int64
+	var current GroupState
+	row := pool.QueryRow(ctx, queries.GetGroupStateNoLockQuery, groupName, namespace)
+	err := row.Scan(&groupID, &current)
+	if err == pgx.ErrNoRows {
+		return "", nil
+	}
+	return current, err
+}
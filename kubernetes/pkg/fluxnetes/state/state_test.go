@@ -0,0 +1,65 @@
+package state
+
+import (
+	"testing"
+)
+
+func TestValidateTransition(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    GroupState
+		to      GroupState
+		wantErr bool
+	}{
+		{name: "new group starts provisional", from: "", to: Provisional},
+		{name: "new group cannot start running", from: "", to: Running, wantErr: true},
+		{name: "provisional to ready", from: Provisional, to: Ready},
+		{name: "provisional cannot be allocated", from: Provisional, to: Allocated, wantErr: true},
+		{name: "ready to submitted", from: Ready, to: Submitted},
+		{name: "ready cannot be allocated", from: Ready, to: Allocated, wantErr: true},
+		{name: "submitted to reserved", from: Submitted, to: Reserved},
+		{name: "submitted to allocated", from: Submitted, to: Allocated},
+		{name: "reserved is submitted again", from: Reserved, to: Submitted},
+		{name: "reserved to allocated", from: Reserved, to: Allocated},
+		{name: "allocated cannot be submitted", from: Allocated, to: Submitted, wantErr: true},
+		{name: "allocated to running", from: Allocated, to: Running},
+		{name: "running to completing", from: Running, to: Completing},
+		{name: "running group deleted is cancelled", from: Running, to: Cancelled},
+		{name: "running cannot be ready", from: Running, to: Ready, wantErr: true},
+		{name: "completing to completed", from: Completing, to: Completed},
+		{name: "completing group deleted is cancelled", from: Completing, to: Cancelled},
+		{name: "completing cannot be ready", from: Completing, to: Ready, wantErr: true},
+		{name: "ready group recreated starts again", from: Ready, to: Provisional},
+		{name: "submitted group recreated starts again", from: Submitted, to: Provisional},
+		{name: "reserved group recreated starts again", from: Reserved, to: Provisional},
+		{name: "allocated group recreated starts again", from: Allocated, to: Provisional},
+		{name: "running group recreated starts again", from: Running, to: Provisional},
+		{name: "completing group recreated starts again", from: Completing, to: Provisional},
+		{name: "completed starts again", from: Completed, to: Provisional},
+		{name: "failed starts again", from: Failed, to: Provisional},
+		{name: "cancelled starts again", from: Cancelled, to: Provisional},
+		{name: "completed cannot run", from: Completed, to: Running, wantErr: true},
+		{name: "unknown state", from: GroupState("unknown"), to: Provisional, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateTransition(tt.from, tt.to)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateTransition(%q, %q) error = %v, wantErr %v", tt.from, tt.to, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIsTerminal(t *testing.T) {
+	for _, s := range []GroupState{Completed, Failed, Cancelled} {
+		if !s.IsTerminal() {
+			t.Errorf("%s should be terminal", s)
+		}
+	}
+	for _, s := range []GroupState{Provisional, Ready, Submitted, Reserved, Allocated, Running, Completing} {
+		if s.IsTerminal() {
+			t.Errorf("%s should not be terminal", s)
+		}
+	}
+}
@@ -43,9 +43,11 @@ type ReservationModel struct {
 // AddtWorkers adds the worker for the queue strategy
 // job worker: a queue to submit jobs to fluxion
 // cleanup worker: a queue to cleanup
+// grow worker: a queue to add pods to elastic groups
 func (EasyBackfill) AddWorkers(workers *river.Workers) {
 	river.AddWorker(workers, &work.JobWorker{})
 	river.AddWorker(workers, &work.CleanupWorker{})
+	river.AddWorker(workers, &work.GrowWorker{})
 }
 
 // Schedule moves pod groups from provisional to workers based on a strategy.
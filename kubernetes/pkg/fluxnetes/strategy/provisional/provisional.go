@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
@@ -14,7 +15,9 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	klog "k8s.io/klog/v2"
 	groups "k8s.io/kubernetes/pkg/scheduler/framework/plugins/fluxnetes/group"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/fluxnetes/labels"
 	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/fluxnetes/queries"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/fluxnetes/state"
 	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/fluxnetes/strategy/workers"
 	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/fluxnetes/types"
 )
@@ -49,6 +52,60 @@ func incrementGroupProvisional(
 
 }
 
+// removeStalePod deletes a previous pod with the same name (but different uid) from the
+// provisional table, and takes it off of the group count.
+func removeStalePod(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	pod *corev1.Pod,
+	group *groups.PodGroup,
+) error {
+
+	result, err := pool.Exec(ctx, queries.DeleteStaleProvisionalPodQuery, group.Name, pod.Namespace, pod.Name, string(pod.UID))
+	if err != nil {
+		return err
+	}
+	stale := result.RowsAffected()
+	if stale == 0 {
+		return nil
+	}
+	klog.Infof("Removed %d stale pod %s/%s from group %s", stale, pod.Namespace, pod.Name, group.Name)
+	_, err = pool.Exec(ctx, queries.DecrementGroupProvisional, stale, group.Name, pod.Namespace)
+	return err
+}
+
+// addToAllocated adds a pod to the provisional table (so it can be bound later) if
+// its group has a flux id, and returns false if the group has not been allocated.
+// The group is not counted again, since it has already moved on to pending.
+func addToAllocated(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	pod *corev1.Pod,
+	group *groups.PodGroup,
+) (bool, error) {
+
+	var fluxID pgtype.Int4
+	err := pool.QueryRow(ctx, queries.GetFluxID, group.Name, pod.Namespace).Scan(&fluxID)
+	if err == pgx.ErrNoRows || (err == nil && !fluxID.Valid) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	podspec, err := json.Marshal(pod)
+	if err != nil {
+		return false, err
+	}
+	ts := &pgtype.Timestamptz{Time: group.Timestamp.Time, Valid: true}
+	query := fmt.Sprintf(queries.InsertIntoProvisionalQuery, string(podspec), pod.Namespace, pod.Name, pod.UID, group.Duration, group.Name, group.Name, pod.Namespace, pod.UID)
+	_, err = pool.Exec(ctx, query, ts)
+	if err != nil {
+		return false, err
+	}
+	klog.Infof("Pod %s/%s grows group %s (flux job id %d)", pod.Namespace, pod.Name, group.Name, fluxID.Int32)
+	return true, nil
+}
+
 // Enqueue adds a pod to the provisional queue, and if not yet added, the group to the group queue.
 // provisional queue. A pool database connection is required,  which comes from the main Fluxnetes queue.
 func (q *ProvisionalQueue) Enqueue(
@@ -64,6 +121,18 @@ func (q *ProvisionalQueue) Enqueue(
 	}
 	defer pool.Close()
 
+	// An elastic group that has an allocation takes the pod, which grows the group
+	if pod.Labels[labels.PodGroupElasticLabel] == "true" {
+		growing, err := addToAllocated(ctx, pool, pod, group)
+		if err != nil {
+			klog.Infof("Error adding pod %s/%s to elastic group %s", pod.Namespace, pod.Name, group.Name)
+			return types.Unknown, err
+		}
+		if growing {
+			return types.GroupGrow, nil
+		}
+	}
+
 	// First check - a pod group in pending is not allowed to enqueue new pods.
 	// This means the job is submit / running (and not completed
 	result, err := pool.Exec(context.Background(), queries.IsPendingQuery, group.Name, pod.Namespace)
@@ -83,10 +152,18 @@ func (q *ProvisionalQueue) Enqueue(
 		return types.PodInvalid, err
 	}
 
+	// A pod with the same name but a different uid was deleted and recreated, and
+	// the old entry (and its contribution to the group size) needs to be removed.
+	err = removeStalePod(ctx, pool, pod, group)
+	if err != nil {
+		klog.Infof("Error removing stale entries for pod %s/%s from provisional queue", pod.Namespace, pod.Name)
+		return types.Unknown, err
+	}
+
 	// Insert or fall back if does not exists to doing nothing
 	// TODO add back timestamp, and optimize this function to minimize database exec calls
 	ts := &pgtype.Timestamptz{Time: group.Timestamp.Time, Valid: true}
-	query := fmt.Sprintf(queries.InsertIntoProvisionalQuery, string(podspec), pod.Namespace, pod.Name, group.Duration, group.Name, group.Name, pod.Namespace, pod.Name)
+	query := fmt.Sprintf(queries.InsertIntoProvisionalQuery, string(podspec), pod.Namespace, pod.Name, pod.UID, group.Duration, group.Name, group.Name, pod.Namespace, pod.UID)
 	_, err = pool.Exec(context.Background(), query, ts)
 	if err != nil {
 		klog.Infof("Error inserting pod %s/%s into provisional queue", pod.Namespace, pod.Name)
@@ -102,11 +179,20 @@ func (q *ProvisionalQueue) Enqueue(
 	// Next add to group provisional - will only add if does not exist
 	// and if so, we make count 1 to avoid incremental call
 	query = fmt.Sprintf(queries.InsertIntoGroupProvisional, group.Name, pod.Namespace, group.Size, group.Duration, string(podspec), group.Name, pod.Namespace)
-	_, err = pool.Exec(ctx, query, ts)
+	result, err = pool.Exec(ctx, query, ts)
 	if err != nil {
 		klog.Infof("Error inserting group into provisional %s", err)
 		return types.Unknown, err
 	}
+
+	// A new group starts its lifecycle as provisional
+	if result.RowsAffected() > 0 {
+		reason := fmt.Sprintf("first pod %s seen", pod.Name)
+		err = state.Transition(ctx, pool, group.Name, pod.Namespace, state.Provisional, reason)
+		if err != nil {
+			klog.Errorf("Error recording group %s state: %s", group.Name, err)
+		}
+	}
 	return types.PodEnqueueSuccess, nil
 }
 
@@ -152,11 +238,14 @@ func (q *ProvisionalQueue) getReadyGroups(ctx context.Context, pool *pgxpool.Poo
 		}
 
 		// Assemble one podspec, and list of pods that we will need
+		// The uids are the identity of the pods, and the names are for the user
 		podlist := []string{}
+		uids := []string{}
 		var podspec string
 		for _, pod := range pods {
 			podspec = pod.Podspec
 			podlist = append(podlist, pod.Name)
+			uids = append(uids, pod.UID)
 		}
 		klog.Infof("parsing group %s", model)
 		jobArgs := workers.JobArgs{
@@ -166,6 +255,7 @@ func (q *ProvisionalQueue) getReadyGroups(ctx context.Context, pool *pgxpool.Poo
 			Podspec:   podspec,
 			Namespace: model.Namespace,
 			Names:     strings.Join(podlist, ","),
+			UIDs:      strings.Join(uids, ","),
 		}
 		lookup[model.GroupName+"-"+model.Namespace] = jobArgs
 	}
@@ -205,6 +295,60 @@ func (q *ProvisionalQueue) deleteGroups(
 	return err
 }
 
+// The longest the satisfy checks for one schedule cycle can take together, since
+// the cycle waits for them
+const satisfyTimeout = 30 * time.Second
+
+// rejectUnsatisfiable asks fluxion if each ready group could ever be matched, and
+// returns the groups that can. The others are removed from the provisional tables
+// and their objects deleted, so they never take a pending or worker slot. If we
+// cannot ask fluxion (or run out of time), the group continues and the worker
+// will find out.
+func (q *ProvisionalQueue) rejectUnsatisfiable(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	jobs []workers.JobArgs,
+) []workers.JobArgs {
+
+	satisfyCtx, cancel := context.WithTimeout(ctx, satisfyTimeout)
+	defer cancel()
+
+	satisfiable := []workers.JobArgs{}
+	for _, job := range jobs {
+		if satisfyCtx.Err() != nil {
+			klog.Infof("No time left to check if group %s/%s is satisfiable", job.Namespace, job.GroupName)
+			satisfiable = append(satisfiable, job)
+			continue
+		}
+		ok, reason, err := workers.Satisfy(satisfyCtx, job)
+		if err != nil {
+			klog.Errorf("Error checking if group %s/%s is satisfiable: %s", job.Namespace, job.GroupName, err)
+			satisfiable = append(satisfiable, job)
+			continue
+		}
+		if ok {
+			satisfiable = append(satisfiable, job)
+			continue
+		}
+		klog.Infof("Group %s/%s is unsatisfiable and is rejected: %s", job.Namespace, job.GroupName, reason)
+		err = q.deleteGroups(ctx, pool, []workers.JobArgs{job})
+		if err != nil {
+			klog.Errorf("Error deleting unsatisfiable group %s/%s: %s", job.Namespace, job.GroupName, err)
+		}
+		err = state.Transition(ctx, pool, job.GroupName, job.Namespace, state.Failed, "unsatisfiable: "+reason)
+		if err != nil {
+			klog.Errorf("Error recording group %s state: %s", job.GroupName, err)
+		}
+
+		// There is no flux id, so this deletes the objects and pods from provisional
+		err = workers.Cleanup(ctx, job.Podspec, int64(-1), true, job.GroupName)
+		if err != nil {
+			klog.Errorf("Error cleaning up unsatisfiable group %s/%s: %s", job.Namespace, job.GroupName, err)
+		}
+	}
+	return satisfiable
+}
+
 // Enqueue adds a pod to the provisional queue. A pool database connection is required,
 // which comes from the main Fluxnetes queue.
 func (q *ProvisionalQueue) insertPending(
@@ -237,6 +381,9 @@ func (q *ProvisionalQueue) ReadyJobs(ctx context.Context, pool *pgxpool.Pool) ([
 		return nil, err
 	}
 
+	// 2. Groups that can never be matched are rejected now, and don't go to pending
+	jobs = q.rejectUnsatisfiable(ctx, pool, jobs)
+
 	klog.Infof("Found %d ready groups %s", len(jobs), jobs)
 	if len(jobs) > 0 {
 
@@ -249,6 +396,16 @@ func (q *ProvisionalQueue) ReadyJobs(ctx context.Context, pool *pgxpool.Pool) ([
 		// 3. Finally, we need to delete them from the provisional tables
 		// If more individual pods are added, they need to be a new group
 		err = q.deleteGroups(ctx, pool, jobs)
+		if err != nil {
+			return nil, err
+		}
+		for _, job := range jobs {
+			reason := fmt.Sprintf("all %d pods seen", job.GroupSize)
+			err := state.Transition(ctx, pool, job.GroupName, job.Namespace, state.Ready, reason)
+			if err != nil {
+				klog.Errorf("Error recording group %s state: %s", job.GroupName, err)
+			}
+		}
 	}
 	return jobs, err
 }
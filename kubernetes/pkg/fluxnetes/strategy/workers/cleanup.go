@@ -9,7 +9,6 @@ import (
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
-	"google.golang.org/grpc"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -21,6 +20,7 @@ import (
 	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/fluxnetes/defaults"
 	pb "k8s.io/kubernetes/pkg/scheduler/framework/plugins/fluxnetes/fluxion-grpc"
 	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/fluxnetes/queries"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/fluxnetes/state"
 
 	"github.com/riverqueue/river"
 )
@@ -48,6 +48,7 @@ func SubmitCleanup(
 	pool *pgxpool.Pool,
 	seconds *int64,
 	podspec string,
+	groupName string,
 	fluxID int64,
 	inKubernetes bool,
 	tags []string,
@@ -75,7 +76,8 @@ func SubmitCleanup(
 		Queue:       "cancel_queue",
 		ScheduledAt: scheduledAt,
 	}
-	_, err = client.InsertTx(ctx, tx, CleanupArgs{FluxID: fluxID, Kubernetes: inKubernetes, Podspec: podspec}, &insertOpts)
+	args := CleanupArgs{GroupName: groupName, FluxID: fluxID, Kubernetes: inKubernetes, Podspec: podspec}
+	_, err = client.InsertTx(ctx, tx, args, &insertOpts)
 	if err != nil {
 		return err
 	}
@@ -174,13 +176,18 @@ func Cleanup(
 		if err != nil && !errors.IsNotFound(err) {
 			return err
 		}
+
+		// A group with an allocation is only deleted here when it runs past its duration
+		if fluxID > -1 {
+			recordCancelled(ctx, podspec, groupName)
+		}
 	}
 
 	// We only delete from fluxion if there is a flux id
 	// A valid fluxID is 0 or greater
 	var err error
 	if fluxID > -1 {
-		err = deleteFluxion(fluxID)
+		err = deleteFluxion(ctx, fluxID)
 		if err != nil {
 			klog.Infof("Error issuing cancel to fluxion for group '%s' and fluxID %d", groupName, fluxID)
 		}
@@ -204,7 +211,7 @@ func Cleanup(
 	defer pool.Close()
 
 	// Delete from pending and pods provisional, meaning we are allowed to accept new pods for the group
-	_, err = pool.Exec(context.Background(), queries.DeleteProvisionalGroupsQuery, groupName, pod.Namespace)
+	_, err = pool.Exec(context.Background(), queries.DeleteProvisionalPodsQuery, groupName, pod.Namespace)
 	if err != nil {
 		klog.Infof("Error deleting Pods %s/%s from provisional queue", pod.Namespace, pod.Name)
 		return err
@@ -219,20 +226,39 @@ func Cleanup(
 	return nil
 }
 
+// recordCancelled transitions a group to cancelled after its objects are deleted
+func recordCancelled(ctx context.Context, podspec, groupName string) {
+	var pod corev1.Pod
+	err := json.Unmarshal([]byte(podspec), &pod)
+	if err != nil {
+		klog.Errorf("Error recording group %s state, podspec did not parse: %s", groupName, err)
+		return
+	}
+	pool, err := pgxpool.New(ctx, os.Getenv("DATABASE_URL"))
+	if err != nil {
+		klog.Errorf("Issue creating new pool during cancel: %s", err)
+		return
+	}
+	defer pool.Close()
+
+	err = state.Transition(ctx, pool, groupName, pod.Namespace, state.Cancelled, "duration exceeded")
+	if err != nil {
+		klog.Errorf("Error recording group %s state: %s", groupName, err)
+	}
+}
+
 // deleteFluxion issues a cancel to Fluxion, our scheduler
-func deleteFluxion(fluxID int64) error {
+func deleteFluxion(ctx context.Context, fluxID int64) error {
 
-	// Connect to the Fluxion service. Returning an error means we retry
+	// Use the connection to the Fluxion service. Returning an error means we retry
 	// see: https://riverqueue.com/docs/job-retries
-	conn, err := grpc.Dial("127.0.0.1:4242", grpc.WithInsecure())
+	fluxion, err := fluxionClient()
 	if err != nil {
 		return fmt.Errorf("[Fluxnetes] AskFlux error connecting to server: %v\n", err)
 	}
-	defer conn.Close()
 
 	//	Tell flux to cancel the job id
-	fluxion := pb.NewFluxionServiceClient(conn)
-	fluxionCtx, cancel := context.WithTimeout(context.Background(), 200*time.Second)
+	fluxionCtx, cancel := fluxionContext(ctx)
 	defer cancel()
 
 	// Prepare the request to cancel
@@ -0,0 +1,108 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	pb "k8s.io/kubernetes/pkg/scheduler/framework/plugins/fluxnetes/fluxion-grpc"
+)
+
+// The longest a request to fluxion can take, if the work it belongs to has no deadline
+const fluxionTimeout = 200 * time.Second
+
+// The workers share one connection to fluxion, which is made on first use. It is
+// only kept once it is made, so an error (e.g., the TLS files are not mounted yet)
+// is tried again on the next request.
+var (
+	fluxionConn      *grpc.ClientConn
+	fluxionConnMutex sync.Mutex
+)
+
+// fluxionConnection returns the connection the workers share, and makes it if
+// we do not have it yet. Like the database, the address (host:port or
+// unix:///path) and the files for mutual TLS come from the environment, and
+// without them we connect to the sidecar without TLS.
+func fluxionConnection() (*grpc.ClientConn, error) {
+	fluxionConnMutex.Lock()
+	defer fluxionConnMutex.Unlock()
+	if fluxionConn != nil {
+		return fluxionConn, nil
+	}
+
+	address := os.Getenv("FLUXION_ADDRESS")
+	if address == "" {
+		address = pb.DefaultAddress
+	}
+	var reloader *pb.TLSReloader
+	files := pb.TLSFiles{
+		Cert: os.Getenv("FLUXION_TLS_CERT"),
+		Key:  os.Getenv("FLUXION_TLS_KEY"),
+		CA:   os.Getenv("FLUXION_TLS_CA"),
+	}
+	if files.Enabled() {
+		var err error
+		reloader, err = pb.NewTLSReloader(files)
+		if err != nil {
+			return nil, err
+		}
+	}
+	conn, err := pb.Dial(
+		address, reloader, os.Getenv("FLUXION_TLS_SERVER_NAME"),
+		grpc.WithConnectParams(grpc.ConnectParams{
+			Backoff:           backoff.DefaultConfig,
+			MinConnectTimeout: 20 * time.Second,
+		}),
+
+		// A request waits for fluxion to be ready (e.g., while it reconnects)
+		// until the context of the work is done, instead of failing right away
+		grpc.WithDefaultCallOptions(grpc.WaitForReady(true)),
+	)
+	if err != nil {
+		return nil, err
+	}
+	fluxionConn = conn
+	return fluxionConn, nil
+}
+
+// fluxionClient returns a client on the connection the workers share. The
+// connection reconnects with backoff when it is lost, and is only used while
+// the health service says fluxion is serving.
+func fluxionClient() (pb.FluxionServiceClient, error) {
+	conn, err := fluxionConnection()
+	if err != nil {
+		return nil, err
+	}
+	return pb.NewFluxionServiceClient(conn), nil
+}
+
+// fluxionContext returns the context for a request to fluxion, which is done
+// when the work it belongs to is done
+func fluxionContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, fluxionTimeout)
+}
+
+// CheckFluxion returns an error if fluxion does not say it is serving on the
+// health service. It uses the shared connection, and does not wait for it.
+func CheckFluxion(ctx context.Context) error {
+	conn, err := fluxionConnection()
+	if err != nil {
+		return err
+	}
+	response, err := healthpb.NewHealthClient(conn).Check(
+		ctx, &healthpb.HealthCheckRequest{Service: pb.HealthService}, grpc.WaitForReady(false),
+	)
+	if err != nil {
+		return err
+	}
+	if response.Status != healthpb.HealthCheckResponse_SERVING {
+		return fmt.Errorf("fluxion is %s", response.Status)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package workers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	corev1 "k8s.io/api/core/v1"
+	klog "k8s.io/klog/v2"
+
+	pb "k8s.io/kubernetes/pkg/scheduler/framework/plugins/fluxnetes/fluxion-grpc"
+
+	"github.com/riverqueue/river"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/fluxnetes/queries"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/fluxnetes/resources"
+)
+
+// The grow worker asks fluxion to add pods to the allocation of a running
+// (elastic) group. The nodes are returned like for a job, so the pods are bound
+// by the same event handler.
+func (args GrowArgs) Kind() string { return "grow" }
+
+type GrowWorker struct {
+	river.WorkerDefaults[GrowArgs]
+}
+
+type GrowArgs struct {
+	Podspec   string `json:"podspec"`
+	GroupName string `json:"groupName"`
+	Namespace string `json:"namespace"`
+	Duration  int32  `json:"duration"`
+
+	// The allocation to grow
+	FluxID int64 `json:"fluxid"`
+
+	// Nodes return to Kubernetes to bind
+	Nodes string `json:"nodes"`
+
+	// Comma separated list of names and uids of the new pods
+	Names string `json:"names"`
+	UIDs  string `json:"uids"`
+}
+
+// Work asks fluxion to grow the group. If the pods cannot be matched now, we
+// return an error so the job is retried.
+func (w GrowWorker) Work(ctx context.Context, job *river.Job[GrowArgs]) error {
+	klog.Infof("[GROW-WORKER-START] Grow group %s (flux job id %d) by pods %s", job.Args.GroupName, job.Args.FluxID, job.Args.Names)
+
+	var pod corev1.Pod
+	err := json.Unmarshal([]byte(job.Args.Podspec), &pod)
+	if err != nil {
+		return err
+	}
+	jobspec := resources.PreparePodJobSpec(&pod, job.Args.GroupName)
+	uids := strings.Split(job.Args.UIDs, ",")
+	allowed, err := allowedNodes(ctx, &pod, job.Args.GroupName)
+	if err != nil {
+		klog.Info(err)
+		return err
+	}
+
+	fluxion, err := fluxionClient()
+	if err != nil {
+		klog.Errorf("[Fluxnetes] Grow error connecting to server: %v", err)
+		return err
+	}
+	fluxionCtx, cancel := fluxionContext(ctx)
+	defer cancel()
+
+	request := &pb.GrowRequest{
+		FluxID:   uint64(job.Args.FluxID),
+		Podspec:  jobspec,
+		Count:    int32(len(uids)),
+		JobName:  job.Args.GroupName,
+		Duration: int64(job.Args.Duration),
+
+		// Empty means any node
+		AllowNodes: allowed,
+	}
+	response, err := fluxion.Grow(fluxionCtx, request)
+	if err != nil {
+		klog.Error("[Fluxnetes] Grow did not receive any response", err)
+		return err
+	}
+	if !response.Allocated {
+		return fmt.Errorf("Fluxion could not grow group %s by %d pods now", job.Args.GroupName, len(uids))
+	}
+
+	nodes := []string{}
+	for _, node := range response.GetNodelist() {
+		for i := 0; i < int(node.Tasks); i++ {
+			nodes = append(nodes, node.NodeID)
+		}
+	}
+	nodeStr := strings.Join(nodes, ",")
+
+	pool, err := pgxpool.New(fluxionCtx, os.Getenv("DATABASE_URL"))
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+	_, err = pool.Exec(fluxionCtx, queries.UpdateNodesQuery, nodeStr, job.ID)
+	if err != nil {
+		return err
+	}
+	klog.Infof("[GROW-WORKER-COMPLETE] nodes allocated %s for group %s (flux job id %d)\n",
+		nodeStr, job.Args.GroupName, job.Args.FluxID)
+	return nil
+}
+
+// Shrink asks fluxion to release nodes from the allocation of a running group
+func Shrink(ctx context.Context, fluxID int64, nodes []string) (*pb.ShrinkResponse, error) {
+	fluxion, err := fluxionClient()
+	if err != nil {
+		return nil, fmt.Errorf("[Fluxnetes] Shrink error connecting to server: %v", err)
+	}
+	fluxionCtx, cancel := fluxionContext(ctx)
+	defer cancel()
+	return fluxion.Shrink(fluxionCtx, &pb.ShrinkRequest{FluxID: uint64(fluxID), Nodes: nodes})
+}
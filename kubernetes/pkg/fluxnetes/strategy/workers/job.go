@@ -6,10 +6,8 @@ import (
 	"fmt"
 	"os"
 	"strings"
-	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
-	"google.golang.org/grpc"
 
 	corev1 "k8s.io/api/core/v1"
 	klog "k8s.io/klog/v2"
@@ -19,6 +17,7 @@ import (
 	"github.com/riverqueue/river"
 	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/fluxnetes/queries"
 	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/fluxnetes/resources"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/fluxnetes/state"
 )
 
 // The job worker submits jobs to fluxion with match allocate
@@ -47,8 +46,16 @@ type JobArgs struct {
 
 	// Comma separated list of names
 	Names string `json:"names"`
+
+	// Comma separated list of pod uids, in the same order as names.
+	// The uid (and not the name) is used to find the pod to bind.
+	UIDs string `json:"uids"`
 }
 
+// NodeFilter returns the nodes the Kubernetes filter plugins allow for a pod.
+// It is set by the queue, and nil means any node.
+var NodeFilter func(ctx context.Context, pod *corev1.Pod) ([]string, error)
+
 // Work performs the AskFlux action. Cases include:
 // Allocated: the job was successful and does not need to be re-queued. We return nil (completed)
 // NotAllocated: the job cannot be allocated and needs to be requeued
@@ -72,18 +79,24 @@ func (w JobWorker) Work(ctx context.Context, job *river.Job[JobArgs]) error {
 	jobspec := resources.PreparePodJobSpec(&pod, job.Args.GroupName)
 	klog.Infof("Prepared pod jobspec %s", jobspec)
 
-	// Connect to the Fluxion service. Returning an error means we retry
+	// The nodes are checked each time we ask, so a node added since the group
+	// was submitted is checked too
+	allowed, err := allowedNodes(ctx, &pod, job.Args.GroupName)
+	if err != nil {
+		klog.Info(err)
+		return err
+	}
+
+	// Use the connection to the Fluxion service. Returning an error means we retry
 	// see: https://riverqueue.com/docs/job-retries
-	conn, err := grpc.Dial("127.0.0.1:4242", grpc.WithInsecure())
+	fluxion, err := fluxionClient()
 	if err != nil {
-		klog.Error("[Fluxnetes] AskFlux error connecting to server: %v\n", err)
+		klog.Errorf("[Fluxnetes] AskFlux error connecting to server: %v", err)
 		return err
 	}
-	defer conn.Close()
 
 	//	Let's ask Flux if we can allocate the job!
-	fluxion := pb.NewFluxionServiceClient(conn)
-	fluxionCtx, cancel := context.WithTimeout(context.Background(), 200*time.Second)
+	fluxionCtx, cancel := fluxionContext(ctx)
 	defer cancel()
 
 	// Prepare the request to allocate.
@@ -96,6 +109,12 @@ func (w JobWorker) Work(ctx context.Context, job *river.Job[JobArgs]) error {
 		Reserve: job.Args.Reservation,
 		Count:   job.Args.GroupSize,
 		JobName: job.Args.GroupName,
+
+		// Without a deadline, fluxion uses its default walltime
+		Duration: int64(job.Args.Duration),
+
+		// Empty means any node
+		AllowNodes: allowed,
 	}
 
 	// An error here is an error with making the request, nothing about
@@ -119,6 +138,7 @@ func (w JobWorker) Work(ctx context.Context, job *river.Job[JobArgs]) error {
 	if !response.Reserved && !response.Allocated {
 		errorMessage := fmt.Sprintf("Fluxion could not allocate nodes for %s, likely Unsatisfiable", job.Args.GroupName)
 		klog.Info(errorMessage)
+		transitionGroup(fluxionCtx, pool, job.Args, state.Failed, "unsatisfiable")
 		return river.JobCancel(fmt.Errorf(errorMessage))
 	}
 
@@ -140,6 +160,7 @@ func (w JobWorker) Work(ctx context.Context, job *river.Job[JobArgs]) error {
 	if !response.Allocated {
 		errorMessage := fmt.Sprintf("Fluxion could not allocate nodes for %s, ETA %d", job.Args.GroupName, response.ReservedAt)
 		klog.Info(errorMessage)
+		transitionGroup(fluxionCtx, pool, job.Args, state.Reserved, fmt.Sprintf("reserved at %d", response.ReservedAt))
 
 		// This will have the job be retried in the queue, still based on sorted schedule time and priority
 		return fmt.Errorf(errorMessage)
@@ -170,13 +191,14 @@ func (w JobWorker) Work(ctx context.Context, job *river.Job[JobArgs]) error {
 	if err != nil {
 		return err
 	}
+	transitionGroup(fluxionCtx, pool, job.Args, state.Allocated, fmt.Sprintf("flux job id %d", fluxID))
 
 	// Kick off a cleaning job for when everything should be cancelled, but only if
 	// there is a deadline set. We can't set a deadline for services, etc.
 	// This is here instead of responding to deletion / termination since a job might
 	// run longer than the duration it is allowed.
 	if job.Args.Duration > 0 {
-		err = SubmitCleanup(ctx, pool, pod.Spec.ActiveDeadlineSeconds, job.Args.Podspec, int64(fluxID), true, []string{})
+		err = SubmitCleanup(ctx, pool, pod.Spec.ActiveDeadlineSeconds, job.Args.Podspec, job.Args.GroupName, int64(fluxID), true, []string{})
 		if err != nil {
 			return err
 		}
@@ -185,3 +207,72 @@ func (w JobWorker) Work(ctx context.Context, job *river.Job[JobArgs]) error {
 		nodeStr, job.Args.GroupName, fluxID)
 	return nil
 }
+
+// allowedNodes runs the filter plugins for a group. When no node passes now, it
+// returns an error so the job is retried (a node can be added or change) instead
+// of failing the group. If the filter cannot run, nodes are not constrained.
+func allowedNodes(ctx context.Context, pod *corev1.Pod, groupName string) ([]string, error) {
+	if NodeFilter == nil {
+		return nil, nil
+	}
+	allowed, err := NodeFilter(ctx, pod)
+	if err != nil {
+		klog.Errorf("Error running filter plugins for group %s, not constraining nodes: %s", groupName, err)
+		return nil, nil
+	}
+	if len(allowed) == 0 {
+		return nil, fmt.Errorf("No node passes the filter plugins for group %s now", groupName)
+	}
+	return allowed, nil
+}
+
+// Satisfy asks Fluxion if a group could ever be matched on the cluster, ignoring
+// what is currently allocated. A group that is not satisfiable comes back with a
+// reason, and an error means we could not ask.
+func Satisfy(ctx context.Context, args JobArgs) (bool, string, error) {
+	var pod corev1.Pod
+	err := json.Unmarshal([]byte(args.Podspec), &pod)
+	if err != nil {
+		return false, "", err
+	}
+	jobspec := resources.PreparePodJobSpec(&pod, args.GroupName)
+
+	fluxion, err := fluxionClient()
+	if err != nil {
+		return false, "", fmt.Errorf("[Fluxnetes] Satisfy error connecting to server: %v", err)
+	}
+	fluxionCtx, cancel := fluxionContext(ctx)
+	defer cancel()
+
+	request := &pb.SatisfyRequest{
+		Podspec: jobspec,
+		Count:   args.GroupSize,
+		JobName: args.GroupName,
+	}
+	response, err := fluxion.Satisfy(fluxionCtx, request)
+	if err != nil {
+		return false, "", err
+	}
+	return response.Satisfiable, response.Reason, nil
+}
+
+// Info asks Fluxion for the reservation or allocation of a flux job id,
+// including the start time (ETA for a reservation) and resources.
+func Info(ctx context.Context, fluxID int64) (*pb.InfoResponse, error) {
+	fluxion, err := fluxionClient()
+	if err != nil {
+		return nil, fmt.Errorf("[Fluxnetes] Info error connecting to server: %v", err)
+	}
+	fluxionCtx, cancel := fluxionContext(ctx)
+	defer cancel()
+	return fluxion.Info(fluxionCtx, &pb.InfoRequest{FluxID: uint64(fluxID)})
+}
+
+// transitionGroup records a group state change. The state is bookkeeping, so
+// an error is logged and does not change the outcome of the work.
+func transitionGroup(ctx context.Context, pool *pgxpool.Pool, args JobArgs, to state.GroupState, reason string) {
+	err := state.Transition(ctx, pool, args.GroupName, args.Namespace, to, reason)
+	if err != nil {
+		klog.Errorf("Error recording group %s state: %s", args.GroupName, err)
+	}
+}
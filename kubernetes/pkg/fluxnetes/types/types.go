@@ -12,6 +12,9 @@ const (
 	// and we do not accept new pods for the group
 	GroupAlreadyInPending
 
+	// The group is elastic and has an allocation, and the pod is added to it
+	GroupGrow
+
 	// The pod is invalid (podspec cannot serialize, etc) and should be discarded
 	PodInvalid
 
@@ -30,8 +33,10 @@ type JobModel struct {
 }
 
 // This collects the individual pod names and podspecs for the group
+// The uid is the identity of the pod, since a name can be reused
 type PodModel struct {
 	Name    string `db:"name"`
+	UID     string `db:"uid"`
 	Podspec string `db:"podspec"`
 }
 
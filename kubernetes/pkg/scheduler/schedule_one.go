@@ -113,13 +113,20 @@ func (sched *Scheduler) ScheduleOne(ctx context.Context) {
 	// If we cannot schedule "unsatisfiable" we delete
 	deletePod := false
 
-	// If the group is already in pending we reject it. We do not
-	// currently support expanding groups that are undergoing processing, unless
-	// it is an explicit update to an object (TBA).
+	// If the group is already in pending we reject it. Only an elastic group
+	// that has an allocation can take more pods, which grows it.
 	if enqueueStatus == types.GroupAlreadyInPending {
 		klog.Infof("Pod %s/%s has group already in pending queue, rejecting.", pod.Namespace, pod.Name)
 		deletePod = true
 
+	} else if enqueueStatus == types.GroupGrow {
+		// The pod is added to the allocation of its group, and bound when fluxion has nodes
+		err = sched.Queue.Grow(pod)
+		if err != nil {
+			klog.Infof("Pod %s/%s cannot grow its group: %s", pod.Namespace, pod.Name, err)
+			logger.Error(err, "Issue with fluxnetes Grow")
+		}
+
 	} else if enqueueStatus == types.PodInvalid {
 		klog.Infof("Pod %s/%s is invalid or erroneous, rejecting.", pod.Namespace, pod.Name)
 		deletePod = true
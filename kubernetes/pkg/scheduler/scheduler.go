@@ -44,6 +44,8 @@ import (
 	"k8s.io/kubernetes/pkg/scheduler/framework/parallelize"
 	frameworkplugins "k8s.io/kubernetes/pkg/scheduler/framework/plugins"
 	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/fluxnetes"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/fluxnetes/readiness"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/fluxnetes/strategy/workers"
 	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/noderesources"
 	frameworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
 	internalcache "k8s.io/kubernetes/pkg/scheduler/internal/cache"
@@ -452,14 +454,6 @@ func (sched *Scheduler) Run(ctx context.Context) {
 	logger := klog.FromContext(ctx)
 	sched.SchedulingQueue.Run(logger)
 
-	// We need to start scheduleOne loop in a dedicated goroutine,
-	// because scheduleOne function hangs on getting the next item
-	// from the SchedulingQueue.
-	// If there are no new pods to schedule, it will be hanging there
-	// and if done in this goroutine it will be blocking closing
-	// SchedulingQueue, in effect causing a deadlock on shutdown.
-	go wait.UntilWithContext(ctx, sched.ScheduleOne, 0)
-
 	// Get a handle to the fluxnetes framework
 	fwk, ok := sched.Profiles["fluxnetes"]
 	if !ok {
@@ -471,11 +465,35 @@ func (sched *Scheduler) Run(ctx context.Context) {
 		klog.Infof("Found active profile for %s", profileName)
 	}
 
+	// The queue needs postgres and fluxion, so we wait for them (instead of
+	// crashing while they start) and are only ready while both work
+	ready := readiness.NewStatus("database", "fluxion")
+	go ready.Serve(ctx)
+	for _, dependency := range []struct {
+		name  string
+		check readiness.Check
+	}{
+		{"database", readiness.CheckDatabase},
+		{"fluxion", workers.CheckFluxion},
+	} {
+		err := ready.Wait(ctx, dependency.name, dependency.check)
+		if err != nil {
+			logger.Error(err, "Stopped waiting for dependency", "name", dependency.name)
+			return
+		}
+		go ready.Watch(ctx, dependency.name, dependency.check)
+	}
+
 	// This is the only added line to start our queue
 	logger.Info("[FLUXNETES]", "Starting", "queue")
 	queue, err := fluxnetes.NewQueue(ctx, fwk)
 	if err != nil {
+		// Without the queue we cannot schedule, so we exit to start again
+		if ctx.Err() != nil {
+			return
+		}
 		logger.Error(err, "Issue with Fluxnetes queue")
+		klog.FlushAndExit(klog.ExitFlushTimeout, 1)
 	}
 	stopping := make(chan struct{})
 
@@ -483,6 +501,16 @@ func (sched *Scheduler) Run(ctx context.Context) {
 	sched.Queue = queue
 	defer sched.Queue.Pool.Close()
 
+	// We need to start scheduleOne loop in a dedicated goroutine,
+	// because scheduleOne function hangs on getting the next item
+	// from the SchedulingQueue.
+	// If there are no new pods to schedule, it will be hanging there
+	// and if done in this goroutine it will be blocking closing
+	// SchedulingQueue, in effect causing a deadlock on shutdown.
+	// This starts after the queue, which only returns when this replica
+	// is the leader, so a standby does not enqueue pods.
+	go wait.UntilWithContext(ctx, sched.ScheduleOne, 0)
+
 	// Get and run the informer (update, delete pod events)
 	go sched.Queue.GetInformer().Run(ctx.Done())
 
@@ -521,7 +549,7 @@ func (sched *Scheduler) Run(ctx context.Context) {
 					continue
 				}
 				nodes := args.GetNodes()
-				podNames := args.GetPodNames()
+				podUIDs := args.GetPodUIDs()
 
 				// A cancel means we cannot satisfy, handle the failure
 				if event.Job.State == "cancelled" {
@@ -552,8 +580,8 @@ func (sched *Scheduler) Run(ctx context.Context) {
 				if len(nodes) > 0 {
 
 					// This should not happen!
-					if len(nodes) != len(podNames) {
-						klog.Infof("WARNING: number of pods (tasks) does not match nodes, found %d and %d\n", len(nodes), len(podNames))
+					if len(nodes) != len(podUIDs) {
+						klog.Infof("WARNING: number of pods (tasks) does not match nodes, found %d and %d\n", len(nodes), len(podUIDs))
 					}
 					podsToActivate := framework.NewPodsToActivate()
 					klog.Infof("Got job with state %s and nodes: %s\n", event.Job.State, nodes)
@@ -562,13 +590,22 @@ func (sched *Scheduler) Run(ctx context.Context) {
 					for i, node := range nodes {
 						plan := ScheduleResult{SuggestedHost: node}
 
-						podName := podNames[i]
+						podUID := podUIDs[i]
 
-						// This is the original podspec associated with the name
+						// This is the original podspec associated with the uid
 						// TODO why would we not be able to retrieve it? And what to do to act on it?
-						bindingPod, err := sched.Queue.GetPodSpec(args.Namespace, podName, args.GroupName)
+						bindingPod, err := sched.Queue.GetPodSpec(args.Namespace, podUID, args.GroupName)
+						if err != nil {
+							klog.Errorf("Getting original podspec for uid %s: %s", podUID, err)
+							continue
+						}
+
+						// If the pod was deleted and recreated with the same name, the live pod
+						// is not the one we matched, and we must not bind it.
+						err = sched.Queue.CheckLivePod(bindingPod)
 						if err != nil {
-							klog.Errorf("Getting original podspec", err)
+							klog.Errorf("Refusing to bind pod %s/%s: %s", bindingPod.Namespace, bindingPod.Name, err)
+							continue
 						}
 						fwk, _ := sched.frameworkForPod(bindingPod)
 
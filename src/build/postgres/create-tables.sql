@@ -2,11 +2,13 @@ CREATE TABLE pods_provisional (
     podspec TEXT NOT NULL,
     namespace TEXT NOT NULL,
     name TEXT NOT NULL, 
+    uid TEXT NOT NULL,
     duration INTEGER NOT NULL,
     created_at timestamptz NOT NULL default NOW(),
     group_name TEXT NOT NULL
 );
-CREATE UNIQUE INDEX group_name_index ON pods_provisional (group_name, namespace, name);
+-- Pods are tracked by uid, since a pod can be deleted and recreated with the same name
+CREATE UNIQUE INDEX group_name_index ON pods_provisional (group_name, namespace, uid);
 
 -- A single row for each group
 CREATE TABLE groups_provisional (
@@ -34,4 +36,46 @@ CREATE TABLE pending_queue (
    flux_id INTEGER
 );
  -- Don't allow inserting the same group name / namespace stwice
-CREATE UNIQUE INDEX pending_key ON pending_queue(group_name, namespace);
\ No newline at end of file
+CREATE UNIQUE INDEX pending_key ON pending_queue(group_name, namespace);
+
+-- Explicit group lifecycle state (provisional, ready, submitted, reserved, allocated,
+-- running, completing, completed, failed, cancelled). A group id is assigned each time
+-- a group (re)starts as provisional, so the same name can be reused.
+CREATE SEQUENCE group_id_seq;
+CREATE TABLE group_state (
+   group_id BIGINT NOT NULL,
+   group_name TEXT NOT NULL,
+   namespace TEXT NOT NULL,
+   state TEXT NOT NULL,
+   updated_at timestamptz NOT NULL default NOW()
+);
+CREATE UNIQUE INDEX group_state_key ON group_state(group_name, namespace);
+
+-- Every transition, with a timestamp and reason
+CREATE TABLE group_state_history (
+   group_id BIGINT NOT NULL,
+   group_name TEXT NOT NULL,
+   namespace TEXT NOT NULL,
+   from_state TEXT NOT NULL,
+   to_state TEXT NOT NULL,
+   reason TEXT NOT NULL,
+   created_at timestamptz NOT NULL default NOW()
+);
+CREATE INDEX group_state_history_key ON group_state_history(group_id);
+
+-- Times for reporting, per group lifecycle
+-- wait_time: first pod seen (provisional) until allocated
+-- time_to_allocation: group ready (all pods seen) until allocated
+-- run_time: first pod running until the group finished
+CREATE VIEW group_times AS
+SELECT group_id, group_name, namespace,
+   min(created_at) FILTER (WHERE to_state = 'provisional') AS provisional_at,
+   min(created_at) FILTER (WHERE to_state = 'ready') AS ready_at,
+   min(created_at) FILTER (WHERE to_state = 'allocated') AS allocated_at,
+   min(created_at) FILTER (WHERE to_state = 'running') AS running_at,
+   max(created_at) FILTER (WHERE to_state IN ('completed', 'failed', 'cancelled')) AS finished_at,
+   min(created_at) FILTER (WHERE to_state = 'allocated') - min(created_at) FILTER (WHERE to_state = 'provisional') AS wait_time,
+   min(created_at) FILTER (WHERE to_state = 'allocated') - min(created_at) FILTER (WHERE to_state = 'ready') AS time_to_allocation,
+   max(created_at) FILTER (WHERE to_state IN ('completed', 'failed', 'cancelled')) - min(created_at) FILTER (WHERE to_state = 'running') AS run_time
+FROM group_state_history
+GROUP BY group_id, group_name, namespace;
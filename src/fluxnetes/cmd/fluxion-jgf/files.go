@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// The fake clientset is only linked into this command, not into the fluxion service.
+
+// newFileClientset returns a clientset that has the nodes and pods in files, e.g.,
+// from kubectl get nodes,pods -A -o yaml. A file can have lists or objects (as
+// yaml documents or json), and other kinds are ignored. Pods are listed by node
+// and phase like in the cluster, so the graph is built the same way.
+func newFileClientset(paths ...string) (kubernetes.Interface, error) {
+	objects := []runtime.Object{}
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		found, err := readObjects(content)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read %s: %w", path, err)
+		}
+		objects = append(objects, found...)
+	}
+
+	clientset := fake.NewSimpleClientset(objects...)
+	clientset.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		selector := action.(k8stesting.ListAction).GetListRestrictions().Fields
+		if selector == nil || selector.Empty() {
+			return false, nil, nil
+		}
+		listed, err := clientset.Tracker().List(corev1.SchemeGroupVersion.WithResource("pods"),
+			corev1.SchemeGroupVersion.WithKind("Pod"), action.GetNamespace())
+		if err != nil {
+			return true, nil, err
+		}
+		pods := listed.(*corev1.PodList)
+		matched := pods.Items[:0]
+		for _, pod := range pods.Items {
+			if selector.Matches(fields.Set{"spec.nodeName": pod.Spec.NodeName, "status.phase": string(pod.Status.Phase)}) {
+				matched = append(matched, pod)
+			}
+		}
+		pods.Items = matched
+		return true, pods, nil
+	})
+	return clientset, nil
+}
+
+// readObjects returns the nodes and pods in yaml or json
+func readObjects(content []byte) ([]runtime.Object, error) {
+	decode := scheme.Codecs.UniversalDeserializer().Decode
+	objects := []runtime.Object{}
+	var add func(raw []byte) error
+	add = func(raw []byte) error {
+		if len(bytes.TrimSpace(raw)) == 0 {
+			return nil
+		}
+		object, _, err := decode(raw, nil, nil)
+		if runtime.IsNotRegisteredError(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch typed := object.(type) {
+		case *corev1.List:
+			for _, item := range typed.Items {
+				err := add(item.Raw)
+				if err != nil {
+					return err
+				}
+			}
+		case *corev1.NodeList:
+			for i := range typed.Items {
+				objects = append(objects, &typed.Items[i])
+			}
+		case *corev1.PodList:
+			for i := range typed.Items {
+				objects = append(objects, &typed.Items[i])
+			}
+		case *corev1.Node, *corev1.Pod:
+			objects = append(objects, object)
+		}
+		return nil
+	}
+
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(content)))
+	for {
+		document, err := reader.Read()
+		if err == io.EOF {
+			return objects, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		err = add(document)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
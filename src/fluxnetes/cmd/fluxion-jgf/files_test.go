@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/converged-computing/fluxnetes/pkg/jgf"
+	utils "github.com/converged-computing/fluxnetes/pkg/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildJGFFromFiles(t *testing.T) {
+
+	// Like kubectl get nodes,pods -A -o yaml, with a pod on node-a, one that finished
+	// on node-b, a control plane, a node to skip, and a kind we do not use
+	dump := `apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: Node
+  metadata:
+    name: node-a
+  status:
+    allocatable: {cpu: "2", memory: 2Gi}
+    conditions: [{type: Ready, status: "True"}]
+- apiVersion: v1
+  kind: Node
+  metadata:
+    name: node-b
+  status:
+    allocatable: {cpu: "2", memory: 2Gi}
+    conditions: [{type: Ready, status: "True"}]
+- apiVersion: v1
+  kind: Node
+  metadata:
+    name: control-plane
+    labels: {node-role.kubernetes.io/control-plane: ""}
+  status:
+    allocatable: {cpu: "2", memory: 2Gi}
+- apiVersion: v1
+  kind: Pod
+  metadata: {name: running, namespace: default}
+  spec:
+    nodeName: node-a
+    containers: [{name: c, resources: {requests: {cpu: "1"}}}]
+  status: {phase: Running}
+- apiVersion: v1
+  kind: Pod
+  metadata: {name: done, namespace: other}
+  spec:
+    nodeName: node-b
+    containers: [{name: c, resources: {requests: {cpu: "1"}}}]
+  status: {phase: Succeeded}
+- apiVersion: example.com/v1
+  kind: Widget
+  metadata: {name: ignored}
+---
+apiVersion: v1
+kind: Node
+metadata:
+  name: skipped
+  labels: {fluxnetes.skip: "true"}
+status:
+  allocatable: {cpu: "2", memory: 2Gi}
+  conditions: [{type: Ready, status: "True"}]
+`
+	path := t.TempDir() + "/cluster.yaml"
+	assert.Nil(t, os.WriteFile(path, []byte(dump), 0644))
+	clientset, err := newFileClientset(path)
+	assert.Nil(t, err)
+	graph, err := utils.BuildJGF(context.Background(), clientset, utils.GraphOptions{SkipLabel: "fluxnetes.skip"})
+	assert.Nil(t, err)
+	assert.Nil(t, graph.Validate())
+
+	// The running pod takes a core from node-a, and the finished pod does not count
+	cores := map[string]int{}
+	for _, node := range graph.Graph.Nodes {
+		if node.Metadata.Type == jgf.CoreType {
+			cores[strings.Split(node.Metadata.Paths["containment"], "/")[3]] += 1
+		}
+	}
+	assert.Equal(t, map[string]int{"node-a0": 1, "node-b1": 2}, cores)
+}
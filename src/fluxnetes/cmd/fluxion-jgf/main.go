@@ -0,0 +1,65 @@
+package main
+
+// fluxion-jgf builds the resource graph (JGF) that the fluxion service would,
+// from a kubeconfig or from nodes and pods saved with kubectl, so a production
+// graph can be reproduced elsewhere (e.g., to attach to a bug report).
+//
+//	kubectl get nodes,pods -A -o yaml > cluster.yaml
+//	fluxion-jgf --from cluster.yaml --out kubecluster.json
+//	fluxion-jgf --kubeconfig ~/.kube/config --out kubecluster.json
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	utils "github.com/converged-computing/fluxnetes/pkg/utils"
+	"k8s.io/client-go/kubernetes"
+)
+
+func main() {
+	kubeconfig := flag.String("kubeconfig", "", "Kubeconfig of the cluster to read nodes and pods from")
+	from := flag.String("from", "", "Comma separated yaml or json files with nodes and pods (e.g., from kubectl get nodes,pods -A -o yaml), instead of a cluster")
+	out := flag.String("out", "kubecluster.json", "File to write the JGF to")
+	graphFlags := utils.NewGraphFlags(flag.CommandLine)
+	flag.Parse()
+
+	graphOptions, err := graphFlags.Options()
+	if err != nil {
+		exit(err)
+	}
+	var clientset kubernetes.Interface
+	if *from != "" {
+		clientset, err = newFileClientset(strings.Split(*from, ",")...)
+	} else if *kubeconfig != "" {
+		clientset, err = utils.GetClientset(*kubeconfig)
+	} else {
+		err = fmt.Errorf("a kubeconfig or files to read from are needed")
+	}
+	if err != nil {
+		exit(err)
+	}
+
+	// The same rules as the fluxion service: the control plane and nodes with
+	// the skip label are left out, and pods take resources from their nodes
+	graph, err := utils.BuildJGF(context.Background(), clientset, graphOptions)
+	if err != nil {
+		exit(err)
+	}
+	err = graph.Validate()
+	if err != nil {
+		exit(err)
+	}
+	err = graph.WriteJGF(*out)
+	if err != nil {
+		exit(err)
+	}
+	fmt.Printf("\nWrote JGF with %d vertices to %s\n", len(graph.Graph.Nodes), *out)
+}
+
+func exit(err error) {
+	fmt.Fprintf(os.Stderr, "fluxion-jgf: %s\n", err)
+	os.Exit(1)
+}
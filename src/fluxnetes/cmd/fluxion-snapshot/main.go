@@ -0,0 +1,120 @@
+package main
+
+// fluxion-snapshot exports the state of fluxion (the graph, allocations and
+// reservations) to a file, and restores it into a (new) sidecar.
+//
+//	fluxion-snapshot export --file snapshot.json
+//	fluxion-snapshot restore --file snapshot.json
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	pb "github.com/converged-computing/fluxnetes/pkg/fluxion-grpc"
+)
+
+const usage = `Usage: fluxion-snapshot <export|restore> [options]
+
+Export writes the state of fluxion to a file (or stdout with -), and restore replaces the state of fluxion with it.
+
+`
+
+func main() {
+	flags := flag.NewFlagSet("fluxion-snapshot", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), usage)
+		flags.PrintDefaults()
+	}
+	address := flags.String("address", pb.DefaultAddress, "Address of the fluxion service (host:port or unix:///path/to/socket)")
+	tlsCert := flags.String("tls-cert", "", "Client certificate for mutual TLS")
+	tlsKey := flags.String("tls-key", "", "Key for the mutual TLS certificate")
+	tlsCA := flags.String("tls-ca", "", "Certificate authority that signs the fluxion certificate")
+	serverName := flags.String("server-name", "", "Name in the fluxion certificate (defaults to the host of the address)")
+	file := flags.String("file", "", "File to write (export) or read (restore), - for stdout or stdin")
+	timeout := flags.Duration("timeout", time.Minute, "Timeout for the request to fluxion")
+
+	if len(os.Args) < 2 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	command := os.Args[1]
+	flags.Parse(os.Args[2:])
+	if *file == "" {
+		exit(fmt.Errorf("a file is needed"))
+	}
+
+	var reloader *pb.TLSReloader
+	var err error
+	tlsFiles := pb.TLSFiles{Cert: *tlsCert, Key: *tlsKey, CA: *tlsCA}
+	if tlsFiles.Enabled() {
+		reloader, err = pb.NewTLSReloader(tlsFiles)
+		if err != nil {
+			exit(err)
+		}
+	}
+	conn, err := pb.Dial(*address, reloader, *serverName)
+	if err != nil {
+		exit(err)
+	}
+	defer conn.Close()
+	client := pb.NewFluxionServiceClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	switch command {
+	case "export":
+		response, err := client.Snapshot(ctx, &pb.SnapshotRequest{})
+		if err != nil {
+			exit(err)
+		}
+		err = writeFile(*file, response.Snapshot)
+		if err != nil {
+			exit(err)
+		}
+		fmt.Fprintf(os.Stderr, "Exported %d allocations and %d reservations\n", response.Allocations, response.Reservations)
+
+	case "restore":
+		snapshot, err := readFile(*file)
+		if err != nil {
+			exit(err)
+		}
+		response, err := client.Restore(ctx, &pb.RestoreRequest{Snapshot: snapshot})
+		if err != nil {
+			exit(err)
+		}
+		fmt.Fprintf(os.Stderr, "Restored %d allocations with match policy %q, %d dropped\n",
+			response.Allocations, response.Policy, response.Dropped)
+
+	default:
+		flags.Usage()
+		os.Exit(2)
+	}
+}
+
+func exit(err error) {
+	fmt.Fprintf(os.Stderr, "fluxion-snapshot: %s\n", err)
+	os.Exit(1)
+}
+
+func writeFile(path, snapshot string) error {
+	if path == "-" {
+		_, err := fmt.Println(snapshot)
+		return err
+	}
+	return os.WriteFile(path, []byte(snapshot+"\n"), 0644)
+}
+
+func readFile(path string) (string, error) {
+	var content []byte
+	var err error
+	if path == "-" {
+		content, err = io.ReadAll(os.Stdin)
+	} else {
+		content, err = os.ReadFile(path)
+	}
+	return string(content), err
+}
@@ -1,19 +1,23 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
-	"net"
-	"strings"
+	"os"
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/keepalive"
 
+	"github.com/converged-computing/fluxnetes/pkg/defaults"
 	"github.com/converged-computing/fluxnetes/pkg/fluxion"
 	pb "github.com/converged-computing/fluxnetes/pkg/fluxion-grpc"
 	"github.com/converged-computing/fluxnetes/pkg/service"
 	svcPb "github.com/converged-computing/fluxnetes/pkg/service-grpc"
+	utils "github.com/converged-computing/fluxnetes/pkg/utils"
 )
 
 const (
@@ -26,35 +30,69 @@ var responsechan chan string
 func main() {
 	fmt.Println("This is the fluxion grpc server")
 	policy := flag.String("policy", "", "Match policy")
-	label := flag.String("label", "", "Label name for fluxnetes dedicated nodes")
+	graphFlags := utils.NewGraphFlags(flag.CommandLine)
+	schedulerName := flag.String("scheduler-name", defaults.SchedulerName, "Name of the scheduler whose pods fluxion allocates, so they are not counted as pods from other schedulers")
+	duration := flag.Int64("default-duration", defaults.Duration, "Walltime (in seconds) for groups without an activeDeadlineSeconds, used to plan reservations")
 	grpcPort := flag.String("port", defaultPort, "Port for grpc service")
+	address := flag.String("address", "", "Address for grpc service (host:port or unix:///path/to/socket), instead of the port")
+	tlsCert := flag.String("tls-cert", "", "Certificate for mutual TLS, reloaded when it changes")
+	tlsKey := flag.String("tls-key", "", "Key for the mutual TLS certificate")
+	tlsCA := flag.String("tls-ca", "", "Certificate authority that signs client certificates for mutual TLS")
 	enableServicePlugin := flag.Bool("external-service", enableExternalService, "Flag to enable the external service (defaults to false)")
 
 	flag.Parse()
 
-	// Ensure our port starts with :
-	port := *grpcPort
-	if !strings.HasPrefix(":", port) {
-		port = fmt.Sprintf(":%s", port)
+	// The address can be a unix socket, and defaults to the port on all interfaces
+	listenAddress := *address
+	if listenAddress == "" {
+		listenAddress = *grpcPort
+	}
+	serverOptions := []grpc.ServerOption{
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			MaxConnectionIdle: 5 * time.Minute,
+		}),
+	}
+	tlsFiles := pb.TLSFiles{Cert: *tlsCert, Key: *tlsKey, CA: *tlsCA}
+	if tlsFiles.Enabled() {
+		reloader, err := pb.NewTLSReloader(tlsFiles)
+		if err != nil {
+			fmt.Printf("[GRPCServer] invalid mutual TLS files: %v\n", err)
+			return
+		}
+		serverOptions = append(serverOptions, pb.ServerOption(reloader))
 	}
 
 	// Fluxion GRPC
-	flux := fluxion.Fluxion{}
-	flux.InitFluxion(*policy, *label)
+	flux := fluxion.Fluxion{DefaultDuration: *duration, SchedulerName: *schedulerName}
+	graphOptions, err := graphFlags.Options()
+	if err != nil {
+		fmt.Printf("[GRPCServer] %v\n", err)
+		return
+	}
+	err = flux.InitFluxion(*policy, graphOptions)
+	if err != nil {
+		fmt.Printf("[GRPCServer] cannot start fluxion: %v\n", err)
+		os.Exit(1)
+	}
 
-	lis, err := net.Listen("tcp", port)
+	// Keep the resource graph up to date as nodes change
+	go flux.WatchCluster(context.Background())
+
+	lis, err := pb.Listen(listenAddress)
 	if err != nil {
 		fmt.Printf("[GRPCServer] failed to listen: %v\n", err)
+		return
 	}
 
 	responsechan = make(chan string)
-	server := grpc.NewServer(
-		grpc.KeepaliveParams(keepalive.ServerParameters{
-			MaxConnectionIdle: 5 * time.Minute,
-		}),
-	)
+	server := grpc.NewServer(serverOptions...)
 	pb.RegisterFluxionServiceServer(server, &flux)
 
+	// Clients only send requests while fluxion reports that it is serving
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(pb.HealthService, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+
 	// External plugin (Kubectl) GRPC
 	// This will eventually be an external GRPC module that can
 	// be shared by fluxnetes (flux-k8s) and fluxnetes-kubectl
@@ -73,6 +111,7 @@ func main() {
 		fmt.Printf("[GRPCServer] failed to serve: %v\n", err)
 	}
 
+	healthServer.Shutdown()
 	flux.Close()
 	fmt.Printf("[GRPCServer] Exiting\n")
 }
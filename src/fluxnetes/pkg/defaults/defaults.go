@@ -2,4 +2,37 @@ package defaults
 
 var (
 	KubernetesJsonGraphFormat = "/home/data/jgf/kubecluster.json"
+
+	// Node labels published as properties in the graph, which node selectors
+	// and required node affinity of a pod are matched against
+	NodeLabels = []string{
+		"kubernetes.io/arch",
+		"kubernetes.io/os",
+		"node.kubernetes.io/instance-type",
+		"topology.kubernetes.io/region",
+		"topology.kubernetes.io/zone",
+	}
+)
+
+const (
+	// The levels of topology domains (type=label) between the cluster and nodes
+	Topology = "subnet=topology.kubernetes.io/zone"
+
+	// Extended resources (name=type:unit) added under nodes in the graph
+	ExtendedResources = "nvidia.com/gpu=gpu"
+
+	// Memory of one memory vertex in the graph
+	MemoryUnit = "1Gi"
+
+	// CPU of one core vertex in the graph
+	CoreUnit = "1"
+
+	// Ephemeral storage of one storage vertex in the graph
+	StorageUnit = "1Gi"
+
+	// Walltime (in seconds) of a group without a deadline
+	Duration = int64(3600)
+
+	// Pods from this scheduler are accounted for by fluxion allocations
+	SchedulerName = "fluxnetes"
 )
@@ -32,6 +32,25 @@ type PodSpec struct {
 	Gpu       int64    `protobuf:"varint,5,opt,name=gpu,proto3" json:"gpu,omitempty"`
 	Storage   int64    `protobuf:"varint,6,opt,name=storage,proto3" json:"storage,omitempty"`
 	Labels    []string `protobuf:"bytes,7,rep,name=labels,proto3" json:"labels,omitempty"`
+	// The node selector and required node affinity of the pod.
+	// These are matched against node labels published as properties.
+	NodeSelector map[string]string   `protobuf:"bytes,8,rep,name=nodeSelector,proto3" json:"nodeSelector,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	NodeAffinity []*NodeSelectorTerm `protobuf:"bytes,9,rep,name=nodeAffinity,proto3" json:"nodeAffinity,omitempty"`
+	// A topology level (e.g., zone or rack) to pack the group into one domain
+	// of, or to spread the group across domains of
+	Pack   string `protobuf:"bytes,10,opt,name=pack,proto3" json:"pack,omitempty"`
+	Spread string `protobuf:"bytes,11,opt,name=spread,proto3" json:"spread,omitempty"`
+	// Extended resources (e.g., nvidia.com/gpu, hugepages-2Mi, example.com/foo)
+	// requested by the pod, by name. Gpu above is nvidia.com/gpu, kept for display.
+	Resources map[string]int64 `protobuf:"bytes,12,rep,name=resources,proto3" json:"resources,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	// CPU requested in millicores. Cpu above is in whole cores (rounded up),
+	// and is used when this is not set.
+	MilliCpu int64 `protobuf:"varint,13,opt,name=milliCpu,proto3" json:"milliCpu,omitempty"`
+	// The group wants whole nodes to itself, with one pod on each
+	Exclusive bool `protobuf:"varint,14,opt,name=exclusive,proto3" json:"exclusive,omitempty"`
+	// The match policy (e.g., low, high, lonode or locality) for the group,
+	// or empty for the default
+	Policy string `protobuf:"bytes,15,opt,name=policy,proto3" json:"policy,omitempty"`
 }
 
 func (x *PodSpec) Reset() {
@@ -115,6 +134,176 @@ func (x *PodSpec) GetLabels() []string {
 	return nil
 }
 
+func (x *PodSpec) GetNodeSelector() map[string]string {
+	if x != nil {
+		return x.NodeSelector
+	}
+	return nil
+}
+
+func (x *PodSpec) GetNodeAffinity() []*NodeSelectorTerm {
+	if x != nil {
+		return x.NodeAffinity
+	}
+	return nil
+}
+
+func (x *PodSpec) GetPack() string {
+	if x != nil {
+		return x.Pack
+	}
+	return ""
+}
+
+func (x *PodSpec) GetSpread() string {
+	if x != nil {
+		return x.Spread
+	}
+	return ""
+}
+
+func (x *PodSpec) GetResources() map[string]int64 {
+	if x != nil {
+		return x.Resources
+	}
+	return nil
+}
+
+func (x *PodSpec) GetMilliCpu() int64 {
+	if x != nil {
+		return x.MilliCpu
+	}
+	return 0
+}
+
+func (x *PodSpec) GetExclusive() bool {
+	if x != nil {
+		return x.Exclusive
+	}
+	return false
+}
+
+func (x *PodSpec) GetPolicy() string {
+	if x != nil {
+		return x.Policy
+	}
+	return ""
+}
+
+// A node affinity term, where all requirements must match.
+// A node can match any of the terms.
+type NodeSelectorTerm struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Requirements []*NodeSelectorRequirement `protobuf:"bytes,1,rep,name=requirements,proto3" json:"requirements,omitempty"`
+}
+
+func (x *NodeSelectorTerm) Reset() {
+	*x = NodeSelectorTerm{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *NodeSelectorTerm) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NodeSelectorTerm) ProtoMessage() {}
+
+func (x *NodeSelectorTerm) ProtoReflect() protoreflect.Message {
+	mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NodeSelectorTerm.ProtoReflect.Descriptor instead.
+func (*NodeSelectorTerm) Descriptor() ([]byte, []int) {
+	return file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *NodeSelectorTerm) GetRequirements() []*NodeSelectorRequirement {
+	if x != nil {
+		return x.Requirements
+	}
+	return nil
+}
+
+// A node affinity requirement, with an operator (In, NotIn, Exists,
+// DoesNotExist, Gt, Lt) on the label key
+type NodeSelectorRequirement struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Key      string   `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Operator string   `protobuf:"bytes,2,opt,name=operator,proto3" json:"operator,omitempty"`
+	Values   []string `protobuf:"bytes,3,rep,name=values,proto3" json:"values,omitempty"`
+}
+
+func (x *NodeSelectorRequirement) Reset() {
+	*x = NodeSelectorRequirement{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *NodeSelectorRequirement) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NodeSelectorRequirement) ProtoMessage() {}
+
+func (x *NodeSelectorRequirement) ProtoReflect() protoreflect.Message {
+	mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NodeSelectorRequirement.ProtoReflect.Descriptor instead.
+func (*NodeSelectorRequirement) Descriptor() ([]byte, []int) {
+	return file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *NodeSelectorRequirement) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *NodeSelectorRequirement) GetOperator() string {
+	if x != nil {
+		return x.Operator
+	}
+	return ""
+}
+
+func (x *NodeSelectorRequirement) GetValues() []string {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
 // The Match request message (allocate, allocate_orelse_reserve)
 // TODO: this currently takes a podspec, and we multiply by a count
 // we should ideally support having a list of different pods
@@ -127,12 +316,19 @@ type MatchRequest struct {
 	Count   int32    `protobuf:"varint,3,opt,name=count,proto3" json:"count,omitempty"`
 	Reserve bool     `protobuf:"varint,4,opt,name=reserve,proto3" json:"reserve,omitempty"`
 	JobName string   `protobuf:"bytes,5,opt,name=jobName,proto3" json:"jobName,omitempty"`
+	// Node names the Kubernetes filter plugins allow (or deny) for the job.
+	// Only one should be set, and both empty means any node can be used.
+	AllowNodes []string `protobuf:"bytes,6,rep,name=allowNodes,proto3" json:"allowNodes,omitempty"`
+	DenyNodes  []string `protobuf:"bytes,7,rep,name=denyNodes,proto3" json:"denyNodes,omitempty"`
+	// The walltime (in seconds) of the group, from activeDeadlineSeconds.
+	// Zero means the group has no deadline, and the default is used.
+	Duration int64 `protobuf:"varint,8,opt,name=duration,proto3" json:"duration,omitempty"`
 }
 
 func (x *MatchRequest) Reset() {
 	*x = MatchRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[1]
+		mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[3]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -145,7 +341,7 @@ func (x *MatchRequest) String() string {
 func (*MatchRequest) ProtoMessage() {}
 
 func (x *MatchRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[1]
+	mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[3]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -158,7 +354,7 @@ func (x *MatchRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MatchRequest.ProtoReflect.Descriptor instead.
 func (*MatchRequest) Descriptor() ([]byte, []int) {
-	return file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_rawDescGZIP(), []int{1}
+	return file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *MatchRequest) GetPodspec() *PodSpec {
@@ -189,6 +385,27 @@ func (x *MatchRequest) GetJobName() string {
 	return ""
 }
 
+func (x *MatchRequest) GetAllowNodes() []string {
+	if x != nil {
+		return x.AllowNodes
+	}
+	return nil
+}
+
+func (x *MatchRequest) GetDenyNodes() []string {
+	if x != nil {
+		return x.DenyNodes
+	}
+	return nil
+}
+
+func (x *MatchRequest) GetDuration() int64 {
+	if x != nil {
+		return x.Duration
+	}
+	return 0
+}
+
 // The Nodes/Cluster Update Status
 type NodeAlloc struct {
 	state         protoimpl.MessageState
@@ -202,7 +419,7 @@ type NodeAlloc struct {
 func (x *NodeAlloc) Reset() {
 	*x = NodeAlloc{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[2]
+		mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -215,7 +432,7 @@ func (x *NodeAlloc) String() string {
 func (*NodeAlloc) ProtoMessage() {}
 
 func (x *NodeAlloc) ProtoReflect() protoreflect.Message {
-	mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[2]
+	mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -228,7 +445,7 @@ func (x *NodeAlloc) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use NodeAlloc.ProtoReflect.Descriptor instead.
 func (*NodeAlloc) Descriptor() ([]byte, []int) {
-	return file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_rawDescGZIP(), []int{2}
+	return file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *NodeAlloc) GetNodeID() string {
@@ -261,23 +478,1058 @@ type MatchResponse struct {
 	Allocated bool `protobuf:"varint,5,opt,name=allocated,proto3" json:"allocated,omitempty"`
 }
 
-func (x *MatchResponse) Reset() {
-	*x = MatchResponse{}
+func (x *MatchResponse) Reset() {
+	*x = MatchResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MatchResponse) ProtoMessage() {}
+
+func (x *MatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MatchResponse.ProtoReflect.Descriptor instead.
+func (*MatchResponse) Descriptor() ([]byte, []int) {
+	return file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *MatchResponse) GetFluxID() uint64 {
+	if x != nil {
+		return x.FluxID
+	}
+	return 0
+}
+
+func (x *MatchResponse) GetNodelist() []*NodeAlloc {
+	if x != nil {
+		return x.Nodelist
+	}
+	return nil
+}
+
+func (x *MatchResponse) GetReserved() bool {
+	if x != nil {
+		return x.Reserved
+	}
+	return false
+}
+
+func (x *MatchResponse) GetReservedAt() int64 {
+	if x != nil {
+		return x.ReservedAt
+	}
+	return 0
+}
+
+func (x *MatchResponse) GetAllocated() bool {
+	if x != nil {
+		return x.Allocated
+	}
+	return false
+}
+
+// The MatchBatch request message. Each request is matched in order, and
+// allocates (or reserves, if it asks to) before the next is matched.
+type MatchBatchRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Requests []*MatchRequest `protobuf:"bytes,1,rep,name=requests,proto3" json:"requests,omitempty"`
+}
+
+func (x *MatchBatchRequest) Reset() {
+	*x = MatchBatchRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MatchBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MatchBatchRequest) ProtoMessage() {}
+
+func (x *MatchBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MatchBatchRequest.ProtoReflect.Descriptor instead.
+func (*MatchBatchRequest) Descriptor() ([]byte, []int) {
+	return file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *MatchBatchRequest) GetRequests() []*MatchRequest {
+	if x != nil {
+		return x.Requests
+	}
+	return nil
+}
+
+// The response for one request in a batch. If the match had an error, nothing
+// is allocated or reserved for it, and the next request is still matched.
+type MatchBatchItem struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Response *MatchResponse `protobuf:"bytes,1,opt,name=response,proto3" json:"response,omitempty"`
+	Error    string         `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+}
+
+func (x *MatchBatchItem) Reset() {
+	*x = MatchBatchItem{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MatchBatchItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MatchBatchItem) ProtoMessage() {}
+
+func (x *MatchBatchItem) ProtoReflect() protoreflect.Message {
+	mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MatchBatchItem.ProtoReflect.Descriptor instead.
+func (*MatchBatchItem) Descriptor() ([]byte, []int) {
+	return file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *MatchBatchItem) GetResponse() *MatchResponse {
+	if x != nil {
+		return x.Response
+	}
+	return nil
+}
+
+func (x *MatchBatchItem) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// The MatchBatch response message, with an item for each request in the same order
+type MatchBatchResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Items []*MatchBatchItem `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+}
+
+func (x *MatchBatchResponse) Reset() {
+	*x = MatchBatchResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MatchBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MatchBatchResponse) ProtoMessage() {}
+
+func (x *MatchBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MatchBatchResponse.ProtoReflect.Descriptor instead.
+func (*MatchBatchResponse) Descriptor() ([]byte, []int) {
+	return file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *MatchBatchResponse) GetItems() []*MatchBatchItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+// The Satisfy request message, the same shape as a match request
+type SatisfyRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Podspec *PodSpec `protobuf:"bytes,1,opt,name=podspec,proto3" json:"podspec,omitempty"`
+	Count   int32    `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	JobName string   `protobuf:"bytes,3,opt,name=jobName,proto3" json:"jobName,omitempty"`
+}
+
+func (x *SatisfyRequest) Reset() {
+	*x = SatisfyRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SatisfyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SatisfyRequest) ProtoMessage() {}
+
+func (x *SatisfyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SatisfyRequest.ProtoReflect.Descriptor instead.
+func (*SatisfyRequest) Descriptor() ([]byte, []int) {
+	return file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *SatisfyRequest) GetPodspec() *PodSpec {
+	if x != nil {
+		return x.Podspec
+	}
+	return nil
+}
+
+func (x *SatisfyRequest) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *SatisfyRequest) GetJobName() string {
+	if x != nil {
+		return x.JobName
+	}
+	return ""
+}
+
+// The Satisfy response message
+type SatisfyResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Satisfiable bool `protobuf:"varint,1,opt,name=satisfiable,proto3" json:"satisfiable,omitempty"`
+	// Why the request cannot be satisfied
+	Reason string `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+}
+
+func (x *SatisfyResponse) Reset() {
+	*x = SatisfyResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SatisfyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SatisfyResponse) ProtoMessage() {}
+
+func (x *SatisfyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SatisfyResponse.ProtoReflect.Descriptor instead.
+func (*SatisfyResponse) Descriptor() ([]byte, []int) {
+	return file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SatisfyResponse) GetSatisfiable() bool {
+	if x != nil {
+		return x.Satisfiable
+	}
+	return false
+}
+
+func (x *SatisfyResponse) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type InfoRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	FluxID uint64 `protobuf:"varint,1,opt,name=fluxID,proto3" json:"fluxID,omitempty"`
+}
+
+func (x *InfoRequest) Reset() {
+	*x = InfoRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *InfoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InfoRequest) ProtoMessage() {}
+
+func (x *InfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InfoRequest.ProtoReflect.Descriptor instead.
+func (*InfoRequest) Descriptor() ([]byte, []int) {
+	return file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *InfoRequest) GetFluxID() uint64 {
+	if x != nil {
+		return x.FluxID
+	}
+	return 0
+}
+
+// The Info response message
+type InfoResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	FluxID   uint64 `protobuf:"varint,1,opt,name=fluxID,proto3" json:"fluxID,omitempty"`
+	Reserved bool   `protobuf:"varint,2,opt,name=reserved,proto3" json:"reserved,omitempty"`
+	// allocated or reserved
+	Mode string `protobuf:"bytes,3,opt,name=mode,proto3" json:"mode,omitempty"`
+	// Start time, which is the ETA for a reservation
+	At       int64   `protobuf:"varint,4,opt,name=at,proto3" json:"at,omitempty"`
+	Overhead float64 `protobuf:"fixed64,5,opt,name=overhead,proto3" json:"overhead,omitempty"`
+	// The allocated (or reserved) resources, R, and the node names in it
+	Allocated string   `protobuf:"bytes,6,opt,name=allocated,proto3" json:"allocated,omitempty"`
+	Nodes     []string `protobuf:"bytes,7,rep,name=nodes,proto3" json:"nodes,omitempty"`
+}
+
+func (x *InfoResponse) Reset() {
+	*x = InfoResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *InfoResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InfoResponse) ProtoMessage() {}
+
+func (x *InfoResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InfoResponse.ProtoReflect.Descriptor instead.
+func (*InfoResponse) Descriptor() ([]byte, []int) {
+	return file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *InfoResponse) GetFluxID() uint64 {
+	if x != nil {
+		return x.FluxID
+	}
+	return 0
+}
+
+func (x *InfoResponse) GetReserved() bool {
+	if x != nil {
+		return x.Reserved
+	}
+	return false
+}
+
+func (x *InfoResponse) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *InfoResponse) GetAt() int64 {
+	if x != nil {
+		return x.At
+	}
+	return 0
+}
+
+func (x *InfoResponse) GetOverhead() float64 {
+	if x != nil {
+		return x.Overhead
+	}
+	return 0
+}
+
+func (x *InfoResponse) GetAllocated() string {
+	if x != nil {
+		return x.Allocated
+	}
+	return ""
+}
+
+func (x *InfoResponse) GetNodes() []string {
+	if x != nil {
+		return x.Nodes
+	}
+	return nil
+}
+
+type SetPolicyRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Policy string `protobuf:"bytes,1,opt,name=policy,proto3" json:"policy,omitempty"`
+}
+
+func (x *SetPolicyRequest) Reset() {
+	*x = SetPolicyRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SetPolicyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetPolicyRequest) ProtoMessage() {}
+
+func (x *SetPolicyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetPolicyRequest.ProtoReflect.Descriptor instead.
+func (*SetPolicyRequest) Descriptor() ([]byte, []int) {
+	return file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *SetPolicyRequest) GetPolicy() string {
+	if x != nil {
+		return x.Policy
+	}
+	return ""
+}
+
+// The SetPolicy response message, with the default policy before and after
+type SetPolicyResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Previous string `protobuf:"bytes,1,opt,name=previous,proto3" json:"previous,omitempty"`
+	Policy   string `protobuf:"bytes,2,opt,name=policy,proto3" json:"policy,omitempty"`
+}
+
+func (x *SetPolicyResponse) Reset() {
+	*x = SetPolicyResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SetPolicyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetPolicyResponse) ProtoMessage() {}
+
+func (x *SetPolicyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetPolicyResponse.ProtoReflect.Descriptor instead.
+func (*SetPolicyResponse) Descriptor() ([]byte, []int) {
+	return file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *SetPolicyResponse) GetPrevious() string {
+	if x != nil {
+		return x.Previous
+	}
+	return ""
+}
+
+func (x *SetPolicyResponse) GetPolicy() string {
+	if x != nil {
+		return x.Policy
+	}
+	return ""
+}
+
+// The Grow request message, to match more pods for an allocated flux job id.
+// The pods are matched like a group, with the same fields as a match request.
+type GrowRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	FluxID     uint64   `protobuf:"varint,1,opt,name=fluxID,proto3" json:"fluxID,omitempty"`
+	Podspec    *PodSpec `protobuf:"bytes,2,opt,name=podspec,proto3" json:"podspec,omitempty"`
+	Count      int32    `protobuf:"varint,3,opt,name=count,proto3" json:"count,omitempty"`
+	JobName    string   `protobuf:"bytes,4,opt,name=jobName,proto3" json:"jobName,omitempty"`
+	AllowNodes []string `protobuf:"bytes,5,rep,name=allowNodes,proto3" json:"allowNodes,omitempty"`
+	DenyNodes  []string `protobuf:"bytes,6,rep,name=denyNodes,proto3" json:"denyNodes,omitempty"`
+	Duration   int64    `protobuf:"varint,7,opt,name=duration,proto3" json:"duration,omitempty"`
+}
+
+func (x *GrowRequest) Reset() {
+	*x = GrowRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GrowRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GrowRequest) ProtoMessage() {}
+
+func (x *GrowRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GrowRequest.ProtoReflect.Descriptor instead.
+func (*GrowRequest) Descriptor() ([]byte, []int) {
+	return file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *GrowRequest) GetFluxID() uint64 {
+	if x != nil {
+		return x.FluxID
+	}
+	return 0
+}
+
+func (x *GrowRequest) GetPodspec() *PodSpec {
+	if x != nil {
+		return x.Podspec
+	}
+	return nil
+}
+
+func (x *GrowRequest) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *GrowRequest) GetJobName() string {
+	if x != nil {
+		return x.JobName
+	}
+	return ""
+}
+
+func (x *GrowRequest) GetAllowNodes() []string {
+	if x != nil {
+		return x.AllowNodes
+	}
+	return nil
+}
+
+func (x *GrowRequest) GetDenyNodes() []string {
+	if x != nil {
+		return x.DenyNodes
+	}
+	return nil
+}
+
+func (x *GrowRequest) GetDuration() int64 {
+	if x != nil {
+		return x.Duration
+	}
+	return 0
+}
+
+// The Grow response message, with the nodes for the new pods only.
+// Allocated is false if the pods cannot be matched now.
+type GrowResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	FluxID    uint64       `protobuf:"varint,1,opt,name=fluxID,proto3" json:"fluxID,omitempty"`
+	Nodelist  []*NodeAlloc `protobuf:"bytes,2,rep,name=nodelist,proto3" json:"nodelist,omitempty"`
+	Allocated bool         `protobuf:"varint,3,opt,name=allocated,proto3" json:"allocated,omitempty"`
+}
+
+func (x *GrowResponse) Reset() {
+	*x = GrowResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GrowResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GrowResponse) ProtoMessage() {}
+
+func (x *GrowResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GrowResponse.ProtoReflect.Descriptor instead.
+func (*GrowResponse) Descriptor() ([]byte, []int) {
+	return file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *GrowResponse) GetFluxID() uint64 {
+	if x != nil {
+		return x.FluxID
+	}
+	return 0
+}
+
+func (x *GrowResponse) GetNodelist() []*NodeAlloc {
+	if x != nil {
+		return x.Nodelist
+	}
+	return nil
+}
+
+func (x *GrowResponse) GetAllocated() bool {
+	if x != nil {
+		return x.Allocated
+	}
+	return false
+}
+
+// The Shrink request message, with the nodes to release from a flux job id
+type ShrinkRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	FluxID uint64   `protobuf:"varint,1,opt,name=fluxID,proto3" json:"fluxID,omitempty"`
+	Nodes  []string `protobuf:"bytes,2,rep,name=nodes,proto3" json:"nodes,omitempty"`
+}
+
+func (x *ShrinkRequest) Reset() {
+	*x = ShrinkRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ShrinkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShrinkRequest) ProtoMessage() {}
+
+func (x *ShrinkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShrinkRequest.ProtoReflect.Descriptor instead.
+func (*ShrinkRequest) Descriptor() ([]byte, []int) {
+	return file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ShrinkRequest) GetFluxID() uint64 {
+	if x != nil {
+		return x.FluxID
+	}
+	return 0
+}
+
+func (x *ShrinkRequest) GetNodes() []string {
+	if x != nil {
+		return x.Nodes
+	}
+	return nil
+}
+
+// The Shrink response message. Cancelled is true if no nodes are left,
+// and the flux job id is gone.
+type ShrinkResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	FluxID    uint64   `protobuf:"varint,1,opt,name=fluxID,proto3" json:"fluxID,omitempty"`
+	Nodes     []string `protobuf:"bytes,2,rep,name=nodes,proto3" json:"nodes,omitempty"`
+	Cancelled bool     `protobuf:"varint,3,opt,name=cancelled,proto3" json:"cancelled,omitempty"`
+}
+
+func (x *ShrinkResponse) Reset() {
+	*x = ShrinkResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ShrinkResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShrinkResponse) ProtoMessage() {}
+
+func (x *ShrinkResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShrinkResponse.ProtoReflect.Descriptor instead.
+func (*ShrinkResponse) Descriptor() ([]byte, []int) {
+	return file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ShrinkResponse) GetFluxID() uint64 {
+	if x != nil {
+		return x.FluxID
+	}
+	return 0
+}
+
+func (x *ShrinkResponse) GetNodes() []string {
+	if x != nil {
+		return x.Nodes
+	}
+	return nil
+}
+
+func (x *ShrinkResponse) GetCancelled() bool {
+	if x != nil {
+		return x.Cancelled
+	}
+	return false
+}
+
+type SnapshotRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *SnapshotRequest) Reset() {
+	*x = SnapshotRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SnapshotRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SnapshotRequest) ProtoMessage() {}
+
+func (x *SnapshotRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SnapshotRequest.ProtoReflect.Descriptor instead.
+func (*SnapshotRequest) Descriptor() ([]byte, []int) {
+	return file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_rawDescGZIP(), []int{19}
+}
+
+// The Snapshot response message, with the snapshot as JSON
+type SnapshotResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Snapshot     string `protobuf:"bytes,1,opt,name=snapshot,proto3" json:"snapshot,omitempty"`
+	Allocations  int32  `protobuf:"varint,2,opt,name=allocations,proto3" json:"allocations,omitempty"`
+	Reservations int32  `protobuf:"varint,3,opt,name=reservations,proto3" json:"reservations,omitempty"`
+}
+
+func (x *SnapshotResponse) Reset() {
+	*x = SnapshotResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SnapshotResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SnapshotResponse) ProtoMessage() {}
+
+func (x *SnapshotResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SnapshotResponse.ProtoReflect.Descriptor instead.
+func (*SnapshotResponse) Descriptor() ([]byte, []int) {
+	return file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *SnapshotResponse) GetSnapshot() string {
+	if x != nil {
+		return x.Snapshot
+	}
+	return ""
+}
+
+func (x *SnapshotResponse) GetAllocations() int32 {
+	if x != nil {
+		return x.Allocations
+	}
+	return 0
+}
+
+func (x *SnapshotResponse) GetReservations() int32 {
+	if x != nil {
+		return x.Reservations
+	}
+	return 0
+}
+
+type RestoreRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Snapshot string `protobuf:"bytes,1,opt,name=snapshot,proto3" json:"snapshot,omitempty"`
+}
+
+func (x *RestoreRequest) Reset() {
+	*x = RestoreRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RestoreRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreRequest) ProtoMessage() {}
+
+func (x *RestoreRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreRequest.ProtoReflect.Descriptor instead.
+func (*RestoreRequest) Descriptor() ([]byte, []int) {
+	return file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *RestoreRequest) GetSnapshot() string {
+	if x != nil {
+		return x.Snapshot
+	}
+	return ""
+}
+
+// The Restore response message. Reservations, and allocations that do not fit
+// the graph, are dropped.
+type RestoreResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Policy      string `protobuf:"bytes,1,opt,name=policy,proto3" json:"policy,omitempty"`
+	Allocations int32  `protobuf:"varint,2,opt,name=allocations,proto3" json:"allocations,omitempty"`
+	Dropped     int32  `protobuf:"varint,3,opt,name=dropped,proto3" json:"dropped,omitempty"`
+}
+
+func (x *RestoreResponse) Reset() {
+	*x = RestoreResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[3]
+		mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[22]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *MatchResponse) String() string {
+func (x *RestoreResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*MatchResponse) ProtoMessage() {}
+func (*RestoreResponse) ProtoMessage() {}
 
-func (x *MatchResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[3]
+func (x *RestoreResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[22]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -288,44 +1540,30 @@ func (x *MatchResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use MatchResponse.ProtoReflect.Descriptor instead.
-func (*MatchResponse) Descriptor() ([]byte, []int) {
-	return file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_rawDescGZIP(), []int{3}
-}
-
-func (x *MatchResponse) GetFluxID() uint64 {
-	if x != nil {
-		return x.FluxID
-	}
-	return 0
-}
-
-func (x *MatchResponse) GetNodelist() []*NodeAlloc {
-	if x != nil {
-		return x.Nodelist
-	}
-	return nil
+// Deprecated: Use RestoreResponse.ProtoReflect.Descriptor instead.
+func (*RestoreResponse) Descriptor() ([]byte, []int) {
+	return file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_rawDescGZIP(), []int{22}
 }
 
-func (x *MatchResponse) GetReserved() bool {
+func (x *RestoreResponse) GetPolicy() string {
 	if x != nil {
-		return x.Reserved
+		return x.Policy
 	}
-	return false
+	return ""
 }
 
-func (x *MatchResponse) GetReservedAt() int64 {
+func (x *RestoreResponse) GetAllocations() int32 {
 	if x != nil {
-		return x.ReservedAt
+		return x.Allocations
 	}
 	return 0
 }
 
-func (x *MatchResponse) GetAllocated() bool {
+func (x *RestoreResponse) GetDropped() int32 {
 	if x != nil {
-		return x.Allocated
+		return x.Dropped
 	}
-	return false
+	return 0
 }
 
 type CancelRequest struct {
@@ -341,7 +1579,7 @@ type CancelRequest struct {
 func (x *CancelRequest) Reset() {
 	*x = CancelRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[4]
+		mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[23]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -354,7 +1592,7 @@ func (x *CancelRequest) String() string {
 func (*CancelRequest) ProtoMessage() {}
 
 func (x *CancelRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[4]
+	mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[23]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -367,7 +1605,7 @@ func (x *CancelRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CancelRequest.ProtoReflect.Descriptor instead.
 func (*CancelRequest) Descriptor() ([]byte, []int) {
-	return file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_rawDescGZIP(), []int{4}
+	return file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_rawDescGZIP(), []int{23}
 }
 
 func (x *CancelRequest) GetFluxID() uint64 {
@@ -397,7 +1635,7 @@ type CancelResponse struct {
 func (x *CancelResponse) Reset() {
 	*x = CancelResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[5]
+		mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[24]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -410,7 +1648,7 @@ func (x *CancelResponse) String() string {
 func (*CancelResponse) ProtoMessage() {}
 
 func (x *CancelResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[5]
+	mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[24]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -423,7 +1661,7 @@ func (x *CancelResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CancelResponse.ProtoReflect.Descriptor instead.
 func (*CancelResponse) Descriptor() ([]byte, []int) {
-	return file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_rawDescGZIP(), []int{5}
+	return file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_rawDescGZIP(), []int{24}
 }
 
 func (x *CancelResponse) GetFluxID() uint64 {
@@ -458,7 +1696,7 @@ type NodeStatus struct {
 func (x *NodeStatus) Reset() {
 	*x = NodeStatus{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[6]
+		mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[25]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -471,7 +1709,7 @@ func (x *NodeStatus) String() string {
 func (*NodeStatus) ProtoMessage() {}
 
 func (x *NodeStatus) ProtoReflect() protoreflect.Message {
-	mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[6]
+	mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[25]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -484,7 +1722,7 @@ func (x *NodeStatus) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use NodeStatus.ProtoReflect.Descriptor instead.
 func (*NodeStatus) Descriptor() ([]byte, []int) {
-	return file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_rawDescGZIP(), []int{6}
+	return file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_rawDescGZIP(), []int{25}
 }
 
 func (x *NodeStatus) GetCpuAvail() int32 {
@@ -548,7 +1786,7 @@ type JGFRequest struct {
 func (x *JGFRequest) Reset() {
 	*x = JGFRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[7]
+		mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[26]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -561,7 +1799,7 @@ func (x *JGFRequest) String() string {
 func (*JGFRequest) ProtoMessage() {}
 
 func (x *JGFRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[7]
+	mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[26]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -574,7 +1812,7 @@ func (x *JGFRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use JGFRequest.ProtoReflect.Descriptor instead.
 func (*JGFRequest) Descriptor() ([]byte, []int) {
-	return file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_rawDescGZIP(), []int{7}
+	return file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_rawDescGZIP(), []int{26}
 }
 
 func (x *JGFRequest) GetJgf() string {
@@ -596,7 +1834,7 @@ type JGFResponse struct {
 func (x *JGFResponse) Reset() {
 	*x = JGFResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[8]
+		mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[27]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -609,7 +1847,7 @@ func (x *JGFResponse) String() string {
 func (*JGFResponse) ProtoMessage() {}
 
 func (x *JGFResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[8]
+	mi := &file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[27]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -622,7 +1860,7 @@ func (x *JGFResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use JGFResponse.ProtoReflect.Descriptor instead.
 func (*JGFResponse) Descriptor() ([]byte, []int) {
-	return file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_rawDescGZIP(), []int{8}
+	return file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_rawDescGZIP(), []int{27}
 }
 
 func (x *JGFResponse) GetJgf() string {
@@ -638,7 +1876,7 @@ var file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_rawDesc = []byte{
 	0x0a, 0x28, 0x66, 0x6c, 0x75, 0x78, 0x6e, 0x65, 0x74, 0x65, 0x73, 0x2f, 0x70, 0x6b, 0x67, 0x2f,
 	0x66, 0x6c, 0x75, 0x78, 0x69, 0x6f, 0x6e, 0x2d, 0x67, 0x72, 0x70, 0x63, 0x2f, 0x66, 0x6c, 0x75,
 	0x78, 0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x07, 0x66, 0x6c, 0x75, 0x78,
-	0x69, 0x6f, 0x6e, 0x22, 0xa5, 0x01, 0x0a, 0x07, 0x50, 0x6f, 0x64, 0x53, 0x70, 0x65, 0x63, 0x12,
+	0x69, 0x6f, 0x6e, 0x22, 0xe8, 0x04, 0x0a, 0x07, 0x50, 0x6f, 0x64, 0x53, 0x70, 0x65, 0x63, 0x12,
 	0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12,
 	0x1c, 0x0a, 0x09, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01,
 	0x28, 0x09, 0x52, 0x09, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x12, 0x10, 0x0a,
@@ -648,30 +1886,172 @@ var file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_rawDesc = []byte{
 	0x20, 0x01, 0x28, 0x03, 0x52, 0x03, 0x67, 0x70, 0x75, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x74, 0x6f,
 	0x72, 0x61, 0x67, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x73, 0x74, 0x6f, 0x72,
 	0x61, 0x67, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x18, 0x07, 0x20,
-	0x03, 0x28, 0x09, 0x52, 0x06, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x22, 0x84, 0x01, 0x0a, 0x0c,
-	0x4d, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2a, 0x0a, 0x07,
-	0x70, 0x6f, 0x64, 0x73, 0x70, 0x65, 0x63, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e,
-	0x66, 0x6c, 0x75, 0x78, 0x69, 0x6f, 0x6e, 0x2e, 0x50, 0x6f, 0x64, 0x53, 0x70, 0x65, 0x63, 0x52,
-	0x07, 0x70, 0x6f, 0x64, 0x73, 0x70, 0x65, 0x63, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6f, 0x75, 0x6e,
-	0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x18,
-	0x0a, 0x07, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52,
-	0x07, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6a, 0x6f, 0x62, 0x4e,
-	0x61, 0x6d, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6a, 0x6f, 0x62, 0x4e, 0x61,
-	0x6d, 0x65, 0x22, 0x39, 0x0a, 0x09, 0x4e, 0x6f, 0x64, 0x65, 0x41, 0x6c, 0x6c, 0x6f, 0x63, 0x12,
-	0x16, 0x0a, 0x06, 0x6e, 0x6f, 0x64, 0x65, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x06, 0x6e, 0x6f, 0x64, 0x65, 0x49, 0x44, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x61, 0x73, 0x6b, 0x73,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x74, 0x61, 0x73, 0x6b, 0x73, 0x22, 0xb2, 0x01,
-	0x0a, 0x0d, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
-	0x16, 0x0a, 0x06, 0x66, 0x6c, 0x75, 0x78, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52,
-	0x06, 0x66, 0x6c, 0x75, 0x78, 0x49, 0x44, 0x12, 0x2e, 0x0a, 0x08, 0x6e, 0x6f, 0x64, 0x65, 0x6c,
-	0x69, 0x73, 0x74, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x66, 0x6c, 0x75, 0x78,
-	0x69, 0x6f, 0x6e, 0x2e, 0x4e, 0x6f, 0x64, 0x65, 0x41, 0x6c, 0x6c, 0x6f, 0x63, 0x52, 0x08, 0x6e,
-	0x6f, 0x64, 0x65, 0x6c, 0x69, 0x73, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x73, 0x65, 0x72,
-	0x76, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x72, 0x65, 0x73, 0x65, 0x72,
-	0x76, 0x65, 0x64, 0x12, 0x1f, 0x0a, 0x0b, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x64, 0x5f,
-	0x61, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76,
-	0x65, 0x64, 0x41, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x61, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x65,
-	0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x61, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74,
+	0x03, 0x28, 0x09, 0x52, 0x06, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x12, 0x46, 0x0a, 0x0c, 0x6e,
+	0x6f, 0x64, 0x65, 0x53, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x18, 0x08, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x22, 0x2e, 0x66, 0x6c, 0x75, 0x78, 0x69, 0x6f, 0x6e, 0x2e, 0x50, 0x6f, 0x64, 0x53,
+	0x70, 0x65, 0x63, 0x2e, 0x4e, 0x6f, 0x64, 0x65, 0x53, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x6f, 0x72,
+	0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0c, 0x6e, 0x6f, 0x64, 0x65, 0x53, 0x65, 0x6c, 0x65, 0x63,
+	0x74, 0x6f, 0x72, 0x12, 0x3d, 0x0a, 0x0c, 0x6e, 0x6f, 0x64, 0x65, 0x41, 0x66, 0x66, 0x69, 0x6e,
+	0x69, 0x74, 0x79, 0x18, 0x09, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x66, 0x6c, 0x75, 0x78,
+	0x69, 0x6f, 0x6e, 0x2e, 0x4e, 0x6f, 0x64, 0x65, 0x53, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x6f, 0x72,
+	0x54, 0x65, 0x72, 0x6d, 0x52, 0x0c, 0x6e, 0x6f, 0x64, 0x65, 0x41, 0x66, 0x66, 0x69, 0x6e, 0x69,
+	0x74, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x63, 0x6b, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x70, 0x61, 0x63, 0x6b, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x70, 0x72, 0x65, 0x61, 0x64,
+	0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x70, 0x72, 0x65, 0x61, 0x64, 0x12, 0x3d,
+	0x0a, 0x09, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x73, 0x18, 0x0c, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x1f, 0x2e, 0x66, 0x6c, 0x75, 0x78, 0x69, 0x6f, 0x6e, 0x2e, 0x50, 0x6f, 0x64, 0x53,
+	0x70, 0x65, 0x63, 0x2e, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x73, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x52, 0x09, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x73, 0x12, 0x1a, 0x0a,
+	0x08, 0x6d, 0x69, 0x6c, 0x6c, 0x69, 0x43, 0x70, 0x75, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x08, 0x6d, 0x69, 0x6c, 0x6c, 0x69, 0x43, 0x70, 0x75, 0x12, 0x1c, 0x0a, 0x09, 0x65, 0x78, 0x63,
+	0x6c, 0x75, 0x73, 0x69, 0x76, 0x65, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x65, 0x78,
+	0x63, 0x6c, 0x75, 0x73, 0x69, 0x76, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x6f, 0x6c, 0x69, 0x63,
+	0x79, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x1a,
+	0x3f, 0x0a, 0x11, 0x4e, 0x6f, 0x64, 0x65, 0x53, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01,
+	0x1a, 0x3c, 0x0a, 0x0e, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x73, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x58,
+	0x0a, 0x10, 0x4e, 0x6f, 0x64, 0x65, 0x53, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x54, 0x65,
+	0x72, 0x6d, 0x12, 0x44, 0x0a, 0x0c, 0x72, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x6d, 0x65, 0x6e,
+	0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x66, 0x6c, 0x75, 0x78, 0x69,
+	0x6f, 0x6e, 0x2e, 0x4e, 0x6f, 0x64, 0x65, 0x53, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x52,
+	0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x0c, 0x72, 0x65, 0x71, 0x75,
+	0x69, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x22, 0x5f, 0x0a, 0x17, 0x4e, 0x6f, 0x64, 0x65,
+	0x53, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x52, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x6d,
+	0x65, 0x6e, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x6f,
+	0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x6f,
+	0x72, 0x12, 0x16, 0x0a, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28,
+	0x09, 0x52, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x22, 0xde, 0x01, 0x0a, 0x0c, 0x4d, 0x61,
+	0x74, 0x63, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2a, 0x0a, 0x07, 0x70, 0x6f,
+	0x64, 0x73, 0x70, 0x65, 0x63, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x66, 0x6c,
+	0x75, 0x78, 0x69, 0x6f, 0x6e, 0x2e, 0x50, 0x6f, 0x64, 0x53, 0x70, 0x65, 0x63, 0x52, 0x07, 0x70,
+	0x6f, 0x64, 0x73, 0x70, 0x65, 0x63, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x18, 0x0a, 0x07,
+	0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x72,
+	0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6a, 0x6f, 0x62, 0x4e, 0x61, 0x6d,
+	0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6a, 0x6f, 0x62, 0x4e, 0x61, 0x6d, 0x65,
+	0x12, 0x1e, 0x0a, 0x0a, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x4e, 0x6f, 0x64, 0x65, 0x73, 0x18, 0x06,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x4e, 0x6f, 0x64, 0x65, 0x73,
+	0x12, 0x1c, 0x0a, 0x09, 0x64, 0x65, 0x6e, 0x79, 0x4e, 0x6f, 0x64, 0x65, 0x73, 0x18, 0x07, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x09, 0x64, 0x65, 0x6e, 0x79, 0x4e, 0x6f, 0x64, 0x65, 0x73, 0x12, 0x1a,
+	0x0a, 0x08, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x08, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x08, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x39, 0x0a, 0x09, 0x4e, 0x6f,
+	0x64, 0x65, 0x41, 0x6c, 0x6c, 0x6f, 0x63, 0x12, 0x16, 0x0a, 0x06, 0x6e, 0x6f, 0x64, 0x65, 0x49,
+	0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6e, 0x6f, 0x64, 0x65, 0x49, 0x44, 0x12,
+	0x14, 0x0a, 0x05, 0x74, 0x61, 0x73, 0x6b, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05,
+	0x74, 0x61, 0x73, 0x6b, 0x73, 0x22, 0xb2, 0x01, 0x0a, 0x0d, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x66, 0x6c, 0x75, 0x78, 0x49,
+	0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x66, 0x6c, 0x75, 0x78, 0x49, 0x44, 0x12,
+	0x2e, 0x0a, 0x08, 0x6e, 0x6f, 0x64, 0x65, 0x6c, 0x69, 0x73, 0x74, 0x18, 0x02, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x12, 0x2e, 0x66, 0x6c, 0x75, 0x78, 0x69, 0x6f, 0x6e, 0x2e, 0x4e, 0x6f, 0x64, 0x65,
+	0x41, 0x6c, 0x6c, 0x6f, 0x63, 0x52, 0x08, 0x6e, 0x6f, 0x64, 0x65, 0x6c, 0x69, 0x73, 0x74, 0x12,
+	0x1a, 0x0a, 0x08, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x08, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x64, 0x12, 0x1f, 0x0a, 0x0b, 0x72,
+	0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x0a, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x64, 0x41, 0x74, 0x12, 0x1c, 0x0a, 0x09,
+	0x61, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x09, 0x61, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x65, 0x64, 0x22, 0x46, 0x0a, 0x11, 0x4d, 0x61,
+	0x74, 0x63, 0x68, 0x42, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x31, 0x0a, 0x08, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x15, 0x2e, 0x66, 0x6c, 0x75, 0x78, 0x69, 0x6f, 0x6e, 0x2e, 0x4d, 0x61, 0x74, 0x63,
+	0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x52, 0x08, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x73, 0x22, 0x5a, 0x0a, 0x0e, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x42, 0x61, 0x74, 0x63, 0x68,
+	0x49, 0x74, 0x65, 0x6d, 0x12, 0x32, 0x0a, 0x08, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x66, 0x6c, 0x75, 0x78, 0x69, 0x6f, 0x6e,
+	0x2e, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x52, 0x08,
+	0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f,
+	0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x22, 0x43,
+	0x0a, 0x12, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x42, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2d, 0x0a, 0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x66, 0x6c, 0x75, 0x78, 0x69, 0x6f, 0x6e, 0x2e, 0x4d, 0x61,
+	0x74, 0x63, 0x68, 0x42, 0x61, 0x74, 0x63, 0x68, 0x49, 0x74, 0x65, 0x6d, 0x52, 0x05, 0x69, 0x74,
+	0x65, 0x6d, 0x73, 0x22, 0x6c, 0x0a, 0x0e, 0x53, 0x61, 0x74, 0x69, 0x73, 0x66, 0x79, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2a, 0x0a, 0x07, 0x70, 0x6f, 0x64, 0x73, 0x70, 0x65, 0x63,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x66, 0x6c, 0x75, 0x78, 0x69, 0x6f, 0x6e,
+	0x2e, 0x50, 0x6f, 0x64, 0x53, 0x70, 0x65, 0x63, 0x52, 0x07, 0x70, 0x6f, 0x64, 0x73, 0x70, 0x65,
+	0x63, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x6a, 0x6f, 0x62, 0x4e, 0x61,
+	0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6a, 0x6f, 0x62, 0x4e, 0x61, 0x6d,
+	0x65, 0x22, 0x4b, 0x0a, 0x0f, 0x53, 0x61, 0x74, 0x69, 0x73, 0x66, 0x79, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x73, 0x61, 0x74, 0x69, 0x73, 0x66, 0x69, 0x61,
+	0x62, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x73, 0x61, 0x74, 0x69, 0x73,
+	0x66, 0x69, 0x61, 0x62, 0x6c, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x22, 0x25,
+	0x0a, 0x0b, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a,
+	0x06, 0x66, 0x6c, 0x75, 0x78, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x66,
+	0x6c, 0x75, 0x78, 0x49, 0x44, 0x22, 0xb6, 0x01, 0x0a, 0x0c, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x66, 0x6c, 0x75, 0x78, 0x49, 0x44,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x66, 0x6c, 0x75, 0x78, 0x49, 0x44, 0x12, 0x1a,
+	0x0a, 0x08, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x08, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6d, 0x6f,
+	0x64, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x12, 0x0e,
+	0x0a, 0x02, 0x61, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x02, 0x61, 0x74, 0x12, 0x1a,
+	0x0a, 0x08, 0x6f, 0x76, 0x65, 0x72, 0x68, 0x65, 0x61, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x08, 0x6f, 0x76, 0x65, 0x72, 0x68, 0x65, 0x61, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x61, 0x6c,
+	0x6c, 0x6f, 0x63, 0x61, 0x74, 0x65, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x61,
+	0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x65, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x6e, 0x6f, 0x64, 0x65,
+	0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x6e, 0x6f, 0x64, 0x65, 0x73, 0x22, 0x2a,
+	0x0a, 0x10, 0x53, 0x65, 0x74, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x22, 0x47, 0x0a, 0x11, 0x53, 0x65,
+	0x74, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x1a, 0x0a, 0x08, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x08, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x70,
+	0x6f, 0x6c, 0x69, 0x63, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x6f, 0x6c,
+	0x69, 0x63, 0x79, 0x22, 0xdb, 0x01, 0x0a, 0x0b, 0x47, 0x72, 0x6f, 0x77, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x66, 0x6c, 0x75, 0x78, 0x49, 0x44, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x06, 0x66, 0x6c, 0x75, 0x78, 0x49, 0x44, 0x12, 0x2a, 0x0a, 0x07, 0x70,
+	0x6f, 0x64, 0x73, 0x70, 0x65, 0x63, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x66,
+	0x6c, 0x75, 0x78, 0x69, 0x6f, 0x6e, 0x2e, 0x50, 0x6f, 0x64, 0x53, 0x70, 0x65, 0x63, 0x52, 0x07,
+	0x70, 0x6f, 0x64, 0x73, 0x70, 0x65, 0x63, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x18, 0x0a,
+	0x07, 0x6a, 0x6f, 0x62, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07,
+	0x6a, 0x6f, 0x62, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x61, 0x6c, 0x6c, 0x6f, 0x77,
+	0x4e, 0x6f, 0x64, 0x65, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x61, 0x6c, 0x6c,
+	0x6f, 0x77, 0x4e, 0x6f, 0x64, 0x65, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x64, 0x65, 0x6e, 0x79, 0x4e,
+	0x6f, 0x64, 0x65, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x09, 0x52, 0x09, 0x64, 0x65, 0x6e, 0x79,
+	0x4e, 0x6f, 0x64, 0x65, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x18, 0x07, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x22, 0x74, 0x0a, 0x0c, 0x47, 0x72, 0x6f, 0x77, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x16, 0x0a, 0x06, 0x66, 0x6c, 0x75, 0x78, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x06, 0x66, 0x6c, 0x75, 0x78, 0x49, 0x44, 0x12, 0x2e, 0x0a, 0x08, 0x6e, 0x6f, 0x64,
+	0x65, 0x6c, 0x69, 0x73, 0x74, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x66, 0x6c,
+	0x75, 0x78, 0x69, 0x6f, 0x6e, 0x2e, 0x4e, 0x6f, 0x64, 0x65, 0x41, 0x6c, 0x6c, 0x6f, 0x63, 0x52,
+	0x08, 0x6e, 0x6f, 0x64, 0x65, 0x6c, 0x69, 0x73, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x61, 0x6c, 0x6c,
+	0x6f, 0x63, 0x61, 0x74, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x61, 0x6c,
+	0x6c, 0x6f, 0x63, 0x61, 0x74, 0x65, 0x64, 0x22, 0x3d, 0x0a, 0x0d, 0x53, 0x68, 0x72, 0x69, 0x6e,
+	0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x66, 0x6c, 0x75, 0x78,
+	0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x66, 0x6c, 0x75, 0x78, 0x49, 0x44,
+	0x12, 0x14, 0x0a, 0x05, 0x6e, 0x6f, 0x64, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52,
+	0x05, 0x6e, 0x6f, 0x64, 0x65, 0x73, 0x22, 0x5c, 0x0a, 0x0e, 0x53, 0x68, 0x72, 0x69, 0x6e, 0x6b,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x66, 0x6c, 0x75, 0x78,
+	0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x66, 0x6c, 0x75, 0x78, 0x49, 0x44,
+	0x12, 0x14, 0x0a, 0x05, 0x6e, 0x6f, 0x64, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52,
+	0x05, 0x6e, 0x6f, 0x64, 0x65, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x61, 0x6e, 0x63, 0x65, 0x6c,
+	0x6c, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x63, 0x61, 0x6e, 0x63, 0x65,
+	0x6c, 0x6c, 0x65, 0x64, 0x22, 0x11, 0x0a, 0x0f, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x74, 0x0a, 0x10, 0x53, 0x6e, 0x61, 0x70, 0x73,
+	0x68, 0x6f, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x73,
+	0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x73,
+	0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x12, 0x20, 0x0a, 0x0b, 0x61, 0x6c, 0x6c, 0x6f, 0x63,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x61, 0x6c,
+	0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x22, 0x0a, 0x0c, 0x72, 0x65, 0x73,
+	0x65, 0x72, 0x76, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x0c, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0x2c, 0x0a,
+	0x0e, 0x52, 0x65, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x1a, 0x0a, 0x08, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x08, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x22, 0x65, 0x0a, 0x0f, 0x52,
+	0x65, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x16,
+	0x0a, 0x06, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06,
+	0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x20, 0x0a, 0x0b, 0x61, 0x6c, 0x6c, 0x6f, 0x63, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x61, 0x6c, 0x6c,
+	0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x64, 0x72, 0x6f, 0x70,
+	0x70, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x64, 0x72, 0x6f, 0x70, 0x70,
 	0x65, 0x64, 0x22, 0x45, 0x0a, 0x0d, 0x43, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x52, 0x65, 0x71, 0x75,
 	0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x66, 0x6c, 0x75, 0x78, 0x49, 0x44, 0x18, 0x01, 0x20,
 	0x01, 0x28, 0x04, 0x52, 0x06, 0x66, 0x6c, 0x75, 0x78, 0x49, 0x44, 0x12, 0x1c, 0x0a, 0x09, 0x4e,
@@ -699,20 +2079,51 @@ var file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_rawDesc = []byte{
 	0x12, 0x10, 0x0a, 0x03, 0x6a, 0x67, 0x66, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6a,
 	0x67, 0x66, 0x22, 0x1f, 0x0a, 0x0b, 0x4a, 0x47, 0x46, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
 	0x65, 0x12, 0x10, 0x0a, 0x03, 0x6a, 0x67, 0x66, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03,
-	0x6a, 0x67, 0x66, 0x32, 0x87, 0x01, 0x0a, 0x0e, 0x46, 0x6c, 0x75, 0x78, 0x69, 0x6f, 0x6e, 0x53,
+	0x6a, 0x67, 0x66, 0x32, 0x84, 0x05, 0x0a, 0x0e, 0x46, 0x6c, 0x75, 0x78, 0x69, 0x6f, 0x6e, 0x53,
 	0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x38, 0x0a, 0x05, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x12,
 	0x15, 0x2e, 0x66, 0x6c, 0x75, 0x78, 0x69, 0x6f, 0x6e, 0x2e, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x52,
 	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x66, 0x6c, 0x75, 0x78, 0x69, 0x6f, 0x6e,
 	0x2e, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00,
-	0x12, 0x3b, 0x0a, 0x06, 0x43, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x12, 0x16, 0x2e, 0x66, 0x6c, 0x75,
-	0x78, 0x69, 0x6f, 0x6e, 0x2e, 0x43, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65,
-	0x73, 0x74, 0x1a, 0x17, 0x2e, 0x66, 0x6c, 0x75, 0x78, 0x69, 0x6f, 0x6e, 0x2e, 0x43, 0x61, 0x6e,
-	0x63, 0x65, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x42, 0x3b, 0x5a,
-	0x39, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x63, 0x6f, 0x6e, 0x76,
-	0x65, 0x72, 0x67, 0x65, 0x64, 0x2d, 0x63, 0x6f, 0x6d, 0x70, 0x75, 0x74, 0x69, 0x6e, 0x67, 0x2f,
-	0x66, 0x6c, 0x75, 0x78, 0x6e, 0x65, 0x74, 0x65, 0x73, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x66, 0x6c,
-	0x75, 0x78, 0x69, 0x6f, 0x6e, 0x2d, 0x67, 0x72, 0x70, 0x63, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x33,
+	0x12, 0x47, 0x0a, 0x0a, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x42, 0x61, 0x74, 0x63, 0x68, 0x12, 0x1a,
+	0x2e, 0x66, 0x6c, 0x75, 0x78, 0x69, 0x6f, 0x6e, 0x2e, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x42, 0x61,
+	0x74, 0x63, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x66, 0x6c, 0x75,
+	0x78, 0x69, 0x6f, 0x6e, 0x2e, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x42, 0x61, 0x74, 0x63, 0x68, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x3b, 0x0a, 0x06, 0x43, 0x61, 0x6e,
+	0x63, 0x65, 0x6c, 0x12, 0x16, 0x2e, 0x66, 0x6c, 0x75, 0x78, 0x69, 0x6f, 0x6e, 0x2e, 0x43, 0x61,
+	0x6e, 0x63, 0x65, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x66, 0x6c,
+	0x75, 0x78, 0x69, 0x6f, 0x6e, 0x2e, 0x43, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x3e, 0x0a, 0x07, 0x53, 0x61, 0x74, 0x69, 0x73, 0x66,
+	0x79, 0x12, 0x17, 0x2e, 0x66, 0x6c, 0x75, 0x78, 0x69, 0x6f, 0x6e, 0x2e, 0x53, 0x61, 0x74, 0x69,
+	0x73, 0x66, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x66, 0x6c, 0x75,
+	0x78, 0x69, 0x6f, 0x6e, 0x2e, 0x53, 0x61, 0x74, 0x69, 0x73, 0x66, 0x79, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x35, 0x0a, 0x04, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x14,
+	0x2e, 0x66, 0x6c, 0x75, 0x78, 0x69, 0x6f, 0x6e, 0x2e, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e, 0x66, 0x6c, 0x75, 0x78, 0x69, 0x6f, 0x6e, 0x2e, 0x49,
+	0x6e, 0x66, 0x6f, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x44, 0x0a,
+	0x09, 0x53, 0x65, 0x74, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x19, 0x2e, 0x66, 0x6c, 0x75,
+	0x78, 0x69, 0x6f, 0x6e, 0x2e, 0x53, 0x65, 0x74, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x66, 0x6c, 0x75, 0x78, 0x69, 0x6f, 0x6e, 0x2e,
+	0x53, 0x65, 0x74, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x22, 0x00, 0x12, 0x35, 0x0a, 0x04, 0x47, 0x72, 0x6f, 0x77, 0x12, 0x14, 0x2e, 0x66, 0x6c,
+	0x75, 0x78, 0x69, 0x6f, 0x6e, 0x2e, 0x47, 0x72, 0x6f, 0x77, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x15, 0x2e, 0x66, 0x6c, 0x75, 0x78, 0x69, 0x6f, 0x6e, 0x2e, 0x47, 0x72, 0x6f, 0x77,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x3b, 0x0a, 0x06, 0x53, 0x68,
+	0x72, 0x69, 0x6e, 0x6b, 0x12, 0x16, 0x2e, 0x66, 0x6c, 0x75, 0x78, 0x69, 0x6f, 0x6e, 0x2e, 0x53,
+	0x68, 0x72, 0x69, 0x6e, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x66,
+	0x6c, 0x75, 0x78, 0x69, 0x6f, 0x6e, 0x2e, 0x53, 0x68, 0x72, 0x69, 0x6e, 0x6b, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x41, 0x0a, 0x08, 0x53, 0x6e, 0x61, 0x70, 0x73,
+	0x68, 0x6f, 0x74, 0x12, 0x18, 0x2e, 0x66, 0x6c, 0x75, 0x78, 0x69, 0x6f, 0x6e, 0x2e, 0x53, 0x6e,
+	0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e,
+	0x66, 0x6c, 0x75, 0x78, 0x69, 0x6f, 0x6e, 0x2e, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x3e, 0x0a, 0x07, 0x52, 0x65,
+	0x73, 0x74, 0x6f, 0x72, 0x65, 0x12, 0x17, 0x2e, 0x66, 0x6c, 0x75, 0x78, 0x69, 0x6f, 0x6e, 0x2e,
+	0x52, 0x65, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18,
+	0x2e, 0x66, 0x6c, 0x75, 0x78, 0x69, 0x6f, 0x6e, 0x2e, 0x52, 0x65, 0x73, 0x74, 0x6f, 0x72, 0x65,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x42, 0x3b, 0x5a, 0x39, 0x67, 0x69,
+	0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x63, 0x6f, 0x6e, 0x76, 0x65, 0x72, 0x67,
+	0x65, 0x64, 0x2d, 0x63, 0x6f, 0x6d, 0x70, 0x75, 0x74, 0x69, 0x6e, 0x67, 0x2f, 0x66, 0x6c, 0x75,
+	0x78, 0x6e, 0x65, 0x74, 0x65, 0x73, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x66, 0x6c, 0x75, 0x78, 0x69,
+	0x6f, 0x6e, 0x2d, 0x67, 0x72, 0x70, 0x63, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -727,30 +2138,77 @@ func file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_rawDescGZIP() []byte {
 	return file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_rawDescData
 }
 
-var file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes = make([]protoimpl.MessageInfo, 30)
 var file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_goTypes = []interface{}{
-	(*PodSpec)(nil),        // 0: fluxion.PodSpec
-	(*MatchRequest)(nil),   // 1: fluxion.MatchRequest
-	(*NodeAlloc)(nil),      // 2: fluxion.NodeAlloc
-	(*MatchResponse)(nil),  // 3: fluxion.MatchResponse
-	(*CancelRequest)(nil),  // 4: fluxion.CancelRequest
-	(*CancelResponse)(nil), // 5: fluxion.CancelResponse
-	(*NodeStatus)(nil),     // 6: fluxion.NodeStatus
-	(*JGFRequest)(nil),     // 7: fluxion.JGFRequest
-	(*JGFResponse)(nil),    // 8: fluxion.JGFResponse
+	(*PodSpec)(nil),                 // 0: fluxion.PodSpec
+	(*NodeSelectorTerm)(nil),        // 1: fluxion.NodeSelectorTerm
+	(*NodeSelectorRequirement)(nil), // 2: fluxion.NodeSelectorRequirement
+	(*MatchRequest)(nil),            // 3: fluxion.MatchRequest
+	(*NodeAlloc)(nil),               // 4: fluxion.NodeAlloc
+	(*MatchResponse)(nil),           // 5: fluxion.MatchResponse
+	(*MatchBatchRequest)(nil),       // 6: fluxion.MatchBatchRequest
+	(*MatchBatchItem)(nil),          // 7: fluxion.MatchBatchItem
+	(*MatchBatchResponse)(nil),      // 8: fluxion.MatchBatchResponse
+	(*SatisfyRequest)(nil),          // 9: fluxion.SatisfyRequest
+	(*SatisfyResponse)(nil),         // 10: fluxion.SatisfyResponse
+	(*InfoRequest)(nil),             // 11: fluxion.InfoRequest
+	(*InfoResponse)(nil),            // 12: fluxion.InfoResponse
+	(*SetPolicyRequest)(nil),        // 13: fluxion.SetPolicyRequest
+	(*SetPolicyResponse)(nil),       // 14: fluxion.SetPolicyResponse
+	(*GrowRequest)(nil),             // 15: fluxion.GrowRequest
+	(*GrowResponse)(nil),            // 16: fluxion.GrowResponse
+	(*ShrinkRequest)(nil),           // 17: fluxion.ShrinkRequest
+	(*ShrinkResponse)(nil),          // 18: fluxion.ShrinkResponse
+	(*SnapshotRequest)(nil),         // 19: fluxion.SnapshotRequest
+	(*SnapshotResponse)(nil),        // 20: fluxion.SnapshotResponse
+	(*RestoreRequest)(nil),          // 21: fluxion.RestoreRequest
+	(*RestoreResponse)(nil),         // 22: fluxion.RestoreResponse
+	(*CancelRequest)(nil),           // 23: fluxion.CancelRequest
+	(*CancelResponse)(nil),          // 24: fluxion.CancelResponse
+	(*NodeStatus)(nil),              // 25: fluxion.NodeStatus
+	(*JGFRequest)(nil),              // 26: fluxion.JGFRequest
+	(*JGFResponse)(nil),             // 27: fluxion.JGFResponse
+	nil,                             // 28: fluxion.PodSpec.NodeSelectorEntry
+	nil,                             // 29: fluxion.PodSpec.ResourcesEntry
 }
 var file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_depIdxs = []int32{
-	0, // 0: fluxion.MatchRequest.podspec:type_name -> fluxion.PodSpec
-	2, // 1: fluxion.MatchResponse.nodelist:type_name -> fluxion.NodeAlloc
-	1, // 2: fluxion.FluxionService.Match:input_type -> fluxion.MatchRequest
-	4, // 3: fluxion.FluxionService.Cancel:input_type -> fluxion.CancelRequest
-	3, // 4: fluxion.FluxionService.Match:output_type -> fluxion.MatchResponse
-	5, // 5: fluxion.FluxionService.Cancel:output_type -> fluxion.CancelResponse
-	4, // [4:6] is the sub-list for method output_type
-	2, // [2:4] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	28, // 0: fluxion.PodSpec.nodeSelector:type_name -> fluxion.PodSpec.NodeSelectorEntry
+	1,  // 1: fluxion.PodSpec.nodeAffinity:type_name -> fluxion.NodeSelectorTerm
+	29, // 2: fluxion.PodSpec.resources:type_name -> fluxion.PodSpec.ResourcesEntry
+	2,  // 3: fluxion.NodeSelectorTerm.requirements:type_name -> fluxion.NodeSelectorRequirement
+	0,  // 4: fluxion.MatchRequest.podspec:type_name -> fluxion.PodSpec
+	4,  // 5: fluxion.MatchResponse.nodelist:type_name -> fluxion.NodeAlloc
+	3,  // 6: fluxion.MatchBatchRequest.requests:type_name -> fluxion.MatchRequest
+	5,  // 7: fluxion.MatchBatchItem.response:type_name -> fluxion.MatchResponse
+	7,  // 8: fluxion.MatchBatchResponse.items:type_name -> fluxion.MatchBatchItem
+	0,  // 9: fluxion.SatisfyRequest.podspec:type_name -> fluxion.PodSpec
+	0,  // 10: fluxion.GrowRequest.podspec:type_name -> fluxion.PodSpec
+	4,  // 11: fluxion.GrowResponse.nodelist:type_name -> fluxion.NodeAlloc
+	3,  // 12: fluxion.FluxionService.Match:input_type -> fluxion.MatchRequest
+	6,  // 13: fluxion.FluxionService.MatchBatch:input_type -> fluxion.MatchBatchRequest
+	23, // 14: fluxion.FluxionService.Cancel:input_type -> fluxion.CancelRequest
+	9,  // 15: fluxion.FluxionService.Satisfy:input_type -> fluxion.SatisfyRequest
+	11, // 16: fluxion.FluxionService.Info:input_type -> fluxion.InfoRequest
+	13, // 17: fluxion.FluxionService.SetPolicy:input_type -> fluxion.SetPolicyRequest
+	15, // 18: fluxion.FluxionService.Grow:input_type -> fluxion.GrowRequest
+	17, // 19: fluxion.FluxionService.Shrink:input_type -> fluxion.ShrinkRequest
+	19, // 20: fluxion.FluxionService.Snapshot:input_type -> fluxion.SnapshotRequest
+	21, // 21: fluxion.FluxionService.Restore:input_type -> fluxion.RestoreRequest
+	5,  // 22: fluxion.FluxionService.Match:output_type -> fluxion.MatchResponse
+	8,  // 23: fluxion.FluxionService.MatchBatch:output_type -> fluxion.MatchBatchResponse
+	24, // 24: fluxion.FluxionService.Cancel:output_type -> fluxion.CancelResponse
+	10, // 25: fluxion.FluxionService.Satisfy:output_type -> fluxion.SatisfyResponse
+	12, // 26: fluxion.FluxionService.Info:output_type -> fluxion.InfoResponse
+	14, // 27: fluxion.FluxionService.SetPolicy:output_type -> fluxion.SetPolicyResponse
+	16, // 28: fluxion.FluxionService.Grow:output_type -> fluxion.GrowResponse
+	18, // 29: fluxion.FluxionService.Shrink:output_type -> fluxion.ShrinkResponse
+	20, // 30: fluxion.FluxionService.Snapshot:output_type -> fluxion.SnapshotResponse
+	22, // 31: fluxion.FluxionService.Restore:output_type -> fluxion.RestoreResponse
+	22, // [22:32] is the sub-list for method output_type
+	12, // [12:22] is the sub-list for method input_type
+	12, // [12:12] is the sub-list for extension type_name
+	12, // [12:12] is the sub-list for extension extendee
+	0,  // [0:12] is the sub-list for field type_name
 }
 
 func init() { file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_init() }
@@ -772,7 +2230,7 @@ func file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_init() {
 			}
 		}
 		file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*MatchRequest); i {
+			switch v := v.(*NodeSelectorTerm); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -784,7 +2242,7 @@ func file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_init() {
 			}
 		}
 		file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*NodeAlloc); i {
+			switch v := v.(*NodeSelectorRequirement); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -796,7 +2254,7 @@ func file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_init() {
 			}
 		}
 		file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*MatchResponse); i {
+			switch v := v.(*MatchRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -808,7 +2266,7 @@ func file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_init() {
 			}
 		}
 		file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*CancelRequest); i {
+			switch v := v.(*NodeAlloc); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -820,7 +2278,7 @@ func file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_init() {
 			}
 		}
 		file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*CancelResponse); i {
+			switch v := v.(*MatchResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -832,7 +2290,7 @@ func file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_init() {
 			}
 		}
 		file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*NodeStatus); i {
+			switch v := v.(*MatchBatchRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -844,7 +2302,7 @@ func file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_init() {
 			}
 		}
 		file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*JGFRequest); i {
+			switch v := v.(*MatchBatchItem); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -856,6 +2314,234 @@ func file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_init() {
 			}
 		}
 		file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MatchBatchResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SatisfyRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SatisfyResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*InfoRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*InfoResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SetPolicyRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SetPolicyResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GrowRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GrowResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ShrinkRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ShrinkResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SnapshotRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SnapshotResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RestoreRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RestoreResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CancelRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CancelResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*NodeStatus); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*JGFRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_msgTypes[27].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*JGFResponse); i {
 			case 0:
 				return &v.state
@@ -874,7 +2560,7 @@ func file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_fluxnetes_pkg_fluxion_grpc_fluxion_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   9,
+			NumMessages:   30,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
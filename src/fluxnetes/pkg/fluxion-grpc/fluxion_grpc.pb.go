@@ -24,7 +24,23 @@ const _ = grpc.SupportPackageIsVersion7
 type FluxionServiceClient interface {
 	// Sends a Match command
 	Match(ctx context.Context, in *MatchRequest, opts ...grpc.CallOption) (*MatchResponse, error)
+	// Matches an ordered list of groups one after another, with no other requests between them
+	MatchBatch(ctx context.Context, in *MatchBatchRequest, opts ...grpc.CallOption) (*MatchBatchResponse, error)
 	Cancel(ctx context.Context, in *CancelRequest, opts ...grpc.CallOption) (*CancelResponse, error)
+	// Checks if a request could ever be matched (ignoring current allocations)
+	Satisfy(ctx context.Context, in *SatisfyRequest, opts ...grpc.CallOption) (*SatisfyResponse, error)
+	// Returns the reservation or allocation for a flux job id
+	Info(ctx context.Context, in *InfoRequest, opts ...grpc.CallOption) (*InfoResponse, error)
+	// Reinitializes fluxion with a new default match policy, keeping allocations
+	SetPolicy(ctx context.Context, in *SetPolicyRequest, opts ...grpc.CallOption) (*SetPolicyResponse, error)
+	// Adds resources to the allocation of a running group
+	Grow(ctx context.Context, in *GrowRequest, opts ...grpc.CallOption) (*GrowResponse, error)
+	// Releases nodes from the allocation of a running group
+	Shrink(ctx context.Context, in *ShrinkRequest, opts ...grpc.CallOption) (*ShrinkResponse, error)
+	// Exports the graph, allocations and reservations
+	Snapshot(ctx context.Context, in *SnapshotRequest, opts ...grpc.CallOption) (*SnapshotResponse, error)
+	// Replaces the graph and allocations with a snapshot
+	Restore(ctx context.Context, in *RestoreRequest, opts ...grpc.CallOption) (*RestoreResponse, error)
 }
 
 type fluxionServiceClient struct {
@@ -44,6 +60,15 @@ func (c *fluxionServiceClient) Match(ctx context.Context, in *MatchRequest, opts
 	return out, nil
 }
 
+func (c *fluxionServiceClient) MatchBatch(ctx context.Context, in *MatchBatchRequest, opts ...grpc.CallOption) (*MatchBatchResponse, error) {
+	out := new(MatchBatchResponse)
+	err := c.cc.Invoke(ctx, "/fluxion.FluxionService/MatchBatch", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *fluxionServiceClient) Cancel(ctx context.Context, in *CancelRequest, opts ...grpc.CallOption) (*CancelResponse, error) {
 	out := new(CancelResponse)
 	err := c.cc.Invoke(ctx, "/fluxion.FluxionService/Cancel", in, out, opts...)
@@ -53,13 +78,92 @@ func (c *fluxionServiceClient) Cancel(ctx context.Context, in *CancelRequest, op
 	return out, nil
 }
 
+func (c *fluxionServiceClient) Satisfy(ctx context.Context, in *SatisfyRequest, opts ...grpc.CallOption) (*SatisfyResponse, error) {
+	out := new(SatisfyResponse)
+	err := c.cc.Invoke(ctx, "/fluxion.FluxionService/Satisfy", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fluxionServiceClient) Info(ctx context.Context, in *InfoRequest, opts ...grpc.CallOption) (*InfoResponse, error) {
+	out := new(InfoResponse)
+	err := c.cc.Invoke(ctx, "/fluxion.FluxionService/Info", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fluxionServiceClient) SetPolicy(ctx context.Context, in *SetPolicyRequest, opts ...grpc.CallOption) (*SetPolicyResponse, error) {
+	out := new(SetPolicyResponse)
+	err := c.cc.Invoke(ctx, "/fluxion.FluxionService/SetPolicy", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fluxionServiceClient) Grow(ctx context.Context, in *GrowRequest, opts ...grpc.CallOption) (*GrowResponse, error) {
+	out := new(GrowResponse)
+	err := c.cc.Invoke(ctx, "/fluxion.FluxionService/Grow", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fluxionServiceClient) Shrink(ctx context.Context, in *ShrinkRequest, opts ...grpc.CallOption) (*ShrinkResponse, error) {
+	out := new(ShrinkResponse)
+	err := c.cc.Invoke(ctx, "/fluxion.FluxionService/Shrink", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fluxionServiceClient) Snapshot(ctx context.Context, in *SnapshotRequest, opts ...grpc.CallOption) (*SnapshotResponse, error) {
+	out := new(SnapshotResponse)
+	err := c.cc.Invoke(ctx, "/fluxion.FluxionService/Snapshot", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fluxionServiceClient) Restore(ctx context.Context, in *RestoreRequest, opts ...grpc.CallOption) (*RestoreResponse, error) {
+	out := new(RestoreResponse)
+	err := c.cc.Invoke(ctx, "/fluxion.FluxionService/Restore", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // FluxionServiceServer is the server API for FluxionService service.
 // All implementations must embed UnimplementedFluxionServiceServer
 // for forward compatibility
 type FluxionServiceServer interface {
 	// Sends a Match command
 	Match(context.Context, *MatchRequest) (*MatchResponse, error)
+	// Matches an ordered list of groups one after another, with no other requests between them
+	MatchBatch(context.Context, *MatchBatchRequest) (*MatchBatchResponse, error)
 	Cancel(context.Context, *CancelRequest) (*CancelResponse, error)
+	// Checks if a request could ever be matched (ignoring current allocations)
+	Satisfy(context.Context, *SatisfyRequest) (*SatisfyResponse, error)
+	// Returns the reservation or allocation for a flux job id
+	Info(context.Context, *InfoRequest) (*InfoResponse, error)
+	// Reinitializes fluxion with a new default match policy, keeping allocations
+	SetPolicy(context.Context, *SetPolicyRequest) (*SetPolicyResponse, error)
+	// Adds resources to the allocation of a running group
+	Grow(context.Context, *GrowRequest) (*GrowResponse, error)
+	// Releases nodes from the allocation of a running group
+	Shrink(context.Context, *ShrinkRequest) (*ShrinkResponse, error)
+	// Exports the graph, allocations and reservations
+	Snapshot(context.Context, *SnapshotRequest) (*SnapshotResponse, error)
+	// Replaces the graph and allocations with a snapshot
+	Restore(context.Context, *RestoreRequest) (*RestoreResponse, error)
 	mustEmbedUnimplementedFluxionServiceServer()
 }
 
@@ -70,9 +174,33 @@ type UnimplementedFluxionServiceServer struct {
 func (UnimplementedFluxionServiceServer) Match(context.Context, *MatchRequest) (*MatchResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Match not implemented")
 }
+func (UnimplementedFluxionServiceServer) MatchBatch(context.Context, *MatchBatchRequest) (*MatchBatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MatchBatch not implemented")
+}
 func (UnimplementedFluxionServiceServer) Cancel(context.Context, *CancelRequest) (*CancelResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Cancel not implemented")
 }
+func (UnimplementedFluxionServiceServer) Satisfy(context.Context, *SatisfyRequest) (*SatisfyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Satisfy not implemented")
+}
+func (UnimplementedFluxionServiceServer) Info(context.Context, *InfoRequest) (*InfoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Info not implemented")
+}
+func (UnimplementedFluxionServiceServer) SetPolicy(context.Context, *SetPolicyRequest) (*SetPolicyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetPolicy not implemented")
+}
+func (UnimplementedFluxionServiceServer) Grow(context.Context, *GrowRequest) (*GrowResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Grow not implemented")
+}
+func (UnimplementedFluxionServiceServer) Shrink(context.Context, *ShrinkRequest) (*ShrinkResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Shrink not implemented")
+}
+func (UnimplementedFluxionServiceServer) Snapshot(context.Context, *SnapshotRequest) (*SnapshotResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Snapshot not implemented")
+}
+func (UnimplementedFluxionServiceServer) Restore(context.Context, *RestoreRequest) (*RestoreResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Restore not implemented")
+}
 func (UnimplementedFluxionServiceServer) mustEmbedUnimplementedFluxionServiceServer() {}
 
 // UnsafeFluxionServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -104,6 +232,24 @@ func _FluxionService_Match_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _FluxionService_MatchBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MatchBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FluxionServiceServer).MatchBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/fluxion.FluxionService/MatchBatch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FluxionServiceServer).MatchBatch(ctx, req.(*MatchBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _FluxionService_Cancel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(CancelRequest)
 	if err := dec(in); err != nil {
@@ -122,6 +268,132 @@ func _FluxionService_Cancel_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _FluxionService_Satisfy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SatisfyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FluxionServiceServer).Satisfy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/fluxion.FluxionService/Satisfy",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FluxionServiceServer).Satisfy(ctx, req.(*SatisfyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FluxionService_Info_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FluxionServiceServer).Info(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/fluxion.FluxionService/Info",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FluxionServiceServer).Info(ctx, req.(*InfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FluxionService_SetPolicy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetPolicyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FluxionServiceServer).SetPolicy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/fluxion.FluxionService/SetPolicy",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FluxionServiceServer).SetPolicy(ctx, req.(*SetPolicyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FluxionService_Grow_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GrowRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FluxionServiceServer).Grow(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/fluxion.FluxionService/Grow",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FluxionServiceServer).Grow(ctx, req.(*GrowRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FluxionService_Shrink_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShrinkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FluxionServiceServer).Shrink(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/fluxion.FluxionService/Shrink",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FluxionServiceServer).Shrink(ctx, req.(*ShrinkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FluxionService_Snapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SnapshotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FluxionServiceServer).Snapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/fluxion.FluxionService/Snapshot",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FluxionServiceServer).Snapshot(ctx, req.(*SnapshotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FluxionService_Restore_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FluxionServiceServer).Restore(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/fluxion.FluxionService/Restore",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FluxionServiceServer).Restore(ctx, req.(*RestoreRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // FluxionService_ServiceDesc is the grpc.ServiceDesc for FluxionService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -133,10 +405,42 @@ var FluxionService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Match",
 			Handler:    _FluxionService_Match_Handler,
 		},
+		{
+			MethodName: "MatchBatch",
+			Handler:    _FluxionService_MatchBatch_Handler,
+		},
 		{
 			MethodName: "Cancel",
 			Handler:    _FluxionService_Cancel_Handler,
 		},
+		{
+			MethodName: "Satisfy",
+			Handler:    _FluxionService_Satisfy_Handler,
+		},
+		{
+			MethodName: "Info",
+			Handler:    _FluxionService_Info_Handler,
+		},
+		{
+			MethodName: "SetPolicy",
+			Handler:    _FluxionService_SetPolicy_Handler,
+		},
+		{
+			MethodName: "Grow",
+			Handler:    _FluxionService_Grow_Handler,
+		},
+		{
+			MethodName: "Shrink",
+			Handler:    _FluxionService_Shrink_Handler,
+		},
+		{
+			MethodName: "Snapshot",
+			Handler:    _FluxionService_Snapshot_Handler,
+		},
+		{
+			MethodName: "Restore",
+			Handler:    _FluxionService_Restore_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "fluxnetes/pkg/fluxion-grpc/fluxion.proto",
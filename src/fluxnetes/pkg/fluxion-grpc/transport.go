@@ -0,0 +1,230 @@
+package fluxion_grpc
+
+// The transport is shared by the fluxion service and the scheduler (which gets a
+// copy of this package), so both agree on addresses and credentials.
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	// Registers client side health checking
+	_ "google.golang.org/grpc/health"
+)
+
+// DefaultAddress is where the fluxion service listens when it is a sidecar
+const DefaultAddress = "127.0.0.1:4242"
+
+// unixPrefix starts the address of a unix domain socket (unix:///path or unix:/path)
+const unixPrefix = "unix:"
+
+// ParseAddress returns the network (tcp or unix) and address to listen on. An
+// address that is only a port (4242 or :4242) listens on all interfaces.
+func ParseAddress(address string) (string, string) {
+	if strings.HasPrefix(address, unixPrefix) {
+		path := strings.TrimPrefix(address, unixPrefix)
+		return "unix", strings.TrimPrefix(path, "//")
+	}
+	if !strings.Contains(address, ":") {
+		address = ":" + address
+	}
+	return "tcp", address
+}
+
+// Listen listens on a tcp address or a unix domain socket. A socket file left
+// from a previous run is removed first.
+func Listen(address string) (net.Listener, error) {
+	network, address := ParseAddress(address)
+	if network == "unix" {
+		err := os.Remove(address)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	return net.Listen(network, address)
+}
+
+// TLSFiles are the certificate, key and certificate authority for mutual TLS.
+// The server and client each present a certificate signed by the authority.
+type TLSFiles struct {
+	Cert string
+	Key  string
+	CA   string
+}
+
+// Enabled is true if any of the files are set, and then all of them must be
+func (files TLSFiles) Enabled() bool {
+	return files.Cert != "" || files.Key != "" || files.CA != ""
+}
+
+// TLSReloader reads the TLS files again when they change (e.g., when cert-manager
+// renews a certificate in a mounted secret), so connections use the new ones.
+type TLSReloader struct {
+	files TLSFiles
+
+	mutex    sync.Mutex
+	modTimes []time.Time
+	cert     *tls.Certificate
+	pool     *x509.CertPool
+}
+
+// NewTLSReloader returns a reloader for the files, and reads them to check they are valid
+func NewTLSReloader(files TLSFiles) (*TLSReloader, error) {
+	if files.Cert == "" || files.Key == "" || files.CA == "" {
+		return nil, fmt.Errorf("mutual TLS needs a certificate, key and certificate authority")
+	}
+	reloader := &TLSReloader{files: files}
+	_, _, err := reloader.Load()
+	if err != nil {
+		return nil, err
+	}
+	return reloader, nil
+}
+
+// Load returns the certificate and authority, read again if a file has changed.
+// If the new files cannot be read (e.g., they are partly written) the ones we
+// have are kept.
+func (r *TLSReloader) Load() (*tls.Certificate, *x509.CertPool, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	modTimes := []time.Time{}
+	for _, path := range []string{r.files.Cert, r.files.Key, r.files.CA} {
+		info, err := os.Stat(path)
+		if err != nil {
+			return r.loaded(err)
+		}
+		modTimes = append(modTimes, info.ModTime())
+	}
+	if r.cert != nil && sameTimes(modTimes, r.modTimes) {
+		return r.cert, r.pool, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.files.Cert, r.files.Key)
+	if err != nil {
+		return r.loaded(err)
+	}
+	ca, err := os.ReadFile(r.files.CA)
+	if err != nil {
+		return r.loaded(err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return r.loaded(fmt.Errorf("no certificates in certificate authority %s", r.files.CA))
+	}
+	r.cert, r.pool, r.modTimes = &cert, pool, modTimes
+	return r.cert, r.pool, nil
+}
+
+// loaded returns what we have already read, or the error if we have nothing
+func (r *TLSReloader) loaded(err error) (*tls.Certificate, *x509.CertPool, error) {
+	if r.cert == nil {
+		return nil, nil, err
+	}
+	return r.cert, r.pool, nil
+}
+
+func sameTimes(a, b []time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// ServerOption returns the credentials for the server to require client
+// certificates signed by the authority. The files are checked on each handshake.
+func ServerOption(r *TLSReloader) grpc.ServerOption {
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			cert, pool, err := r.Load()
+			if err != nil {
+				return nil, err
+			}
+			return &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*cert},
+				ClientCAs:    pool,
+				ClientAuth:   tls.RequireAndVerifyClientCert,
+			}, nil
+		},
+	}
+	return grpc.Creds(credentials.NewTLS(config))
+}
+
+// HealthService is the name fluxion reports its status for on the standard
+// gRPC health service, which is the name of the service itself
+var HealthService = FluxionService_ServiceDesc.ServiceName
+
+// healthCheckConfig has the client only use a connection while the health
+// service says fluxion is serving (health checking needs round_robin)
+var healthCheckConfig = fmt.Sprintf(`{"loadBalancingConfig": [{"round_robin": {}}], "healthCheckConfig": {"serviceName": %q}}`, HealthService)
+
+// Dial connects to the fluxion service at an address (host:port or unix:///path).
+// Without a reloader the connection is not encrypted, which is only meant for
+// a sidecar. The server name is the name in the server certificate, and defaults
+// to the host of the address. The connection is meant to be kept: it reconnects
+// when it is lost, and reads the TLS files again for each handshake.
+func Dial(address string, r *TLSReloader, serverName string, options ...grpc.DialOption) (*grpc.ClientConn, error) {
+	options = append(options, grpc.WithDefaultServiceConfig(healthCheckConfig))
+	if r == nil {
+		options = append(options, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		return grpc.Dial(address, options...)
+	}
+	_, _, err := r.Load()
+	if err != nil {
+		return nil, err
+	}
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: serverName,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			cert, _, err := r.Load()
+			return cert, err
+		},
+
+		// The server certificate is verified in VerifyConnection instead, with the
+		// authority we have now (and not the one we had when we dialed)
+		InsecureSkipVerify: true,
+		VerifyConnection: func(state tls.ConnectionState) error {
+			return verifyServer(r, state)
+		},
+	}
+	options = append(options, grpc.WithTransportCredentials(credentials.NewTLS(config)))
+	return grpc.Dial(address, options...)
+}
+
+// verifyServer checks the server certificate is signed by the authority, and for the server name
+func verifyServer(r *TLSReloader, state tls.ConnectionState) error {
+	_, pool, err := r.Load()
+	if err != nil {
+		return err
+	}
+	if len(state.PeerCertificates) == 0 {
+		return fmt.Errorf("fluxion did not present a certificate")
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range state.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err = state.PeerCertificates[0].Verify(x509.VerifyOptions{
+		Roots:         pool,
+		Intermediates: intermediates,
+		DNSName:       state.ServerName,
+	})
+	return err
+}
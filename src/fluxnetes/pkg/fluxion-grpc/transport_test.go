@@ -0,0 +1,183 @@
+package fluxion_grpc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+func TestParseAddress(t *testing.T) {
+	for address, expected := range map[string][2]string{
+		"4242":                     {"tcp", ":4242"},
+		":4242":                    {"tcp", ":4242"},
+		"127.0.0.1:4242":           {"tcp", "127.0.0.1:4242"},
+		"unix:///run/fluxion.sock": {"unix", "/run/fluxion.sock"},
+		"unix:/run/fluxion.sock":   {"unix", "/run/fluxion.sock"},
+	} {
+		network, path := ParseAddress(address)
+		if network != expected[0] || path != expected[1] {
+			t.Errorf("%s: expected %v, got %s %s", address, expected, network, path)
+		}
+	}
+}
+
+// writeCerts writes a new certificate authority, and a certificate and key it
+// signs for localhost, to a directory
+func writeCerts(t *testing.T, dir string) TLSFiles {
+	caKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ca := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "fluxion-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDer, err := x509.CreateCertificate(rand.Reader, ca, ca, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	cert := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "fluxion"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	certDer, err := x509.CreateCertificate(rand.Reader, cert, ca, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, _ := x509.MarshalECPrivateKey(key)
+
+	files := TLSFiles{
+		Cert: filepath.Join(dir, "tls.crt"),
+		Key:  filepath.Join(dir, "tls.key"),
+		CA:   filepath.Join(dir, "ca.crt"),
+	}
+	writePem := func(path, kind string, der []byte) {
+		err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: kind, Bytes: der}), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	writePem(files.Cert, "CERTIFICATE", certDer)
+	writePem(files.Key, "EC PRIVATE KEY", keyDer)
+	writePem(files.CA, "CERTIFICATE", caDer)
+	return files
+}
+
+// copyFiles copies TLS files, with a later modification time so they are read again
+func copyFiles(from, to TLSFiles) {
+	later := time.Now().Add(time.Minute)
+	for _, pair := range [][2]string{{from.Cert, to.Cert}, {from.Key, to.Key}, {from.CA, to.CA}} {
+		content, _ := os.ReadFile(pair[0])
+		os.WriteFile(pair[1], content, 0600)
+		os.Chtimes(pair[1], later, later)
+	}
+}
+
+// infoCode returns the code of an Info call, which is Unimplemented if we get to the service
+func infoCode(address string, r *TLSReloader) codes.Code {
+	conn, err := Dial(address, r, "localhost")
+	if err != nil {
+		return codes.Unknown
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	_, err = NewFluxionServiceClient(conn).Info(ctx, &InfoRequest{})
+	return status.Code(err)
+}
+
+func TestMutualTLSReload(t *testing.T) {
+	serverDir, rotatedDir := t.TempDir(), t.TempDir()
+	files := writeCerts(t, serverDir)
+	serverReloader, err := NewTLSReloader(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	address := "unix://" + filepath.Join(serverDir, "fluxion.sock")
+	lis, err := Listen(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer(ServerOption(serverReloader))
+	RegisterFluxionServiceServer(server, &UnimplementedFluxionServiceServer{})
+	go server.Serve(lis)
+	defer server.Stop()
+
+	// A client with the same authority gets through, and one without a certificate does not
+	oldDir := t.TempDir()
+	old := TLSFiles{Cert: filepath.Join(oldDir, "tls.crt"), Key: filepath.Join(oldDir, "tls.key"), CA: filepath.Join(oldDir, "ca.crt")}
+	copyFiles(files, old)
+	clientReloader, err := NewTLSReloader(old)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code := infoCode(address, clientReloader); code != codes.Unimplemented {
+		t.Errorf("expected a client with a certificate to connect, got %s", code)
+	}
+	if code := infoCode(address, nil); code != codes.Unavailable {
+		t.Errorf("expected a client without a certificate to be refused, got %s", code)
+	}
+
+	// The server reads rotated files, so a client of the old authority is refused
+	// and a client of the new one gets through
+	rotated := writeCerts(t, rotatedDir)
+	copyFiles(rotated, files)
+	if code := infoCode(address, clientReloader); code != codes.Unavailable {
+		t.Errorf("expected a client of the old authority to be refused, got %s", code)
+	}
+	newReloader, err := NewTLSReloader(rotated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code := infoCode(address, newReloader); code != codes.Unimplemented {
+		t.Errorf("expected a client of the new authority to connect, got %s", code)
+	}
+}
+
+func TestHealthCheck(t *testing.T) {
+	address := "unix://" + filepath.Join(t.TempDir(), "fluxion.sock")
+	lis, err := Listen(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer()
+	RegisterFluxionServiceServer(server, &UnimplementedFluxionServiceServer{})
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+	go server.Serve(lis)
+	defer server.Stop()
+
+	// The client does not use fluxion until it says it is serving
+	healthServer.SetServingStatus(HealthService, healthpb.HealthCheckResponse_NOT_SERVING)
+	if code := infoCode(address, nil); code != codes.Unavailable {
+		t.Errorf("expected fluxion that is not serving to be unavailable, got %s", code)
+	}
+	healthServer.SetServingStatus(HealthService, healthpb.HealthCheckResponse_SERVING)
+	if code := infoCode(address, nil); code != codes.Unimplemented {
+		t.Errorf("expected fluxion that is serving to be used, got %s", code)
+	}
+}
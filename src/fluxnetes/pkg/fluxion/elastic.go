@@ -0,0 +1,161 @@
+package fluxion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	pb "github.com/converged-computing/fluxnetes/pkg/fluxion-grpc"
+	"github.com/converged-computing/fluxnetes/pkg/jgf"
+	utils "github.com/converged-computing/fluxnetes/pkg/utils"
+	klog "k8s.io/klog/v2"
+)
+
+// An elastic group can grow and shrink while it runs. Fluxion cannot change an
+// allocation in place, so the resources for the new pods are matched on their own,
+// and the allocation is replaced with the union (or what is left) in each client.
+
+// allocatedJob returns a job that has an allocation, which is all we can change
+func (fluxion *Fluxion) allocatedJob(fluxID uint64) (*job, error) {
+	j, err := fluxion.getJob(fluxID)
+	if err != nil {
+		return nil, err
+	}
+	if j.reserved || j.allocated == "" {
+		return nil, fmt.Errorf("flux job id %d is not allocated", fluxID)
+	}
+	return j, nil
+}
+
+// reallocate replaces the allocation of a job with new resources (R) in each
+// client. A client that cannot take it is made again, like in mirror.
+func (fluxion *Fluxion) reallocate(fluxID uint64, allocated string) {
+	j := fluxion.jobs[fluxID]
+	j.allocated = allocated
+
+	for _, policy := range fluxion.policies() {
+		cli := fluxion.clients[policy]
+		if old, ok := j.ids[policy]; ok {
+			err := cli.Cancel(int64(old), true)
+			if err != nil {
+				klog.Errorf("[Fluxnetes] Cancel of flux job id %d for policy %q: %s", fluxID, policy, err)
+			}
+			delete(j.ids, policy)
+		}
+		id := fluxion.nextReplayID
+		fluxion.nextReplayID += 1
+		_, _, _, err := cli.UpdateAllocate(int(id), allocated)
+		if err == nil {
+			j.ids[policy] = id
+			continue
+		}
+		klog.Errorf("[Fluxnetes] Cannot change flux job id %d in the client for policy %q, making it again: %s", fluxID, policy, cli.GetErrMsg())
+		fluxion.dropClient(policy)
+		if policy == fluxion.policy {
+			_, _, err := fluxion.client(policy)
+			if err != nil {
+				klog.Errorf("[Fluxnetes] %s", err)
+			}
+		}
+	}
+	fluxion.dropOrphans()
+}
+
+// Grow matches more pods for a running group, and adds the resources to its
+// allocation. The pods are matched by the client for the policy of the group.
+// If they cannot be matched now, allocated is false and nothing changes.
+func (fluxion *Fluxion) Grow(ctx context.Context, in *pb.GrowRequest) (*pb.GrowResponse, error) {
+
+	fluxion.mutex.Lock()
+	defer fluxion.mutex.Unlock()
+
+	klog.Infof("[Fluxnetes] Received Grow request %v\n", in)
+	emptyResponse := &pb.GrowResponse{FluxID: in.FluxID}
+
+	j, err := fluxion.allocatedJob(in.FluxID)
+	if err != nil {
+		return nil, err
+	}
+
+	hostConstraint, possible := fluxion.nodeConstraint(in.AllowNodes, in.DenyNodes)
+	if !possible {
+		klog.Infof("[Fluxnetes] None of the allowed nodes for %s are in the graph", in.JobName)
+		return emptyResponse, nil
+	}
+	spec, err := fluxion.matchSpec(in.Podspec, in.Count, in.Duration, hostConstraint)
+	if err != nil {
+		return nil, err
+	}
+
+	cli, _, err := fluxion.jobClient(in.FluxID, j)
+	if err != nil {
+		return nil, err
+	}
+	_, allocated, _, _, jobid, fluxerr := cli.MatchAllocate(false, string(spec))
+	if fluxerr != nil {
+		klog.Errorf("[Fluxnetes] Grow Flux err is %s: %s", fluxerr, cli.GetErrMsg())
+		return nil, errors.New("[Fluxnetes] Error in ReapiCliMatchAllocate")
+	}
+	if allocated == "" {
+		klog.Infof("[Fluxnetes] Cannot grow flux job id %d by %d pods now", in.FluxID, in.Count)
+		return emptyResponse, nil
+	}
+
+	// The new resources are part of the allocation, and not a job of their own
+	merged, err := jgf.MergeAllocated(j.allocated, allocated)
+	cancelErr := cli.Cancel(int64(jobid), true)
+	if err != nil {
+		return nil, fmt.Errorf("cannot add resources to flux job id %d: %w", in.FluxID, err)
+	}
+	if cancelErr != nil {
+		klog.Errorf("[Fluxnetes] Cancel of the match to grow flux job id %d: %s", in.FluxID, cancelErr)
+	}
+	fluxion.reallocate(in.FluxID, merged)
+
+	gr := &pb.GrowResponse{
+		FluxID:    in.FluxID,
+		Nodelist:  fluxion.nodelist(allocated, in.JobName, in.Podspec),
+		Allocated: true,
+	}
+	klog.Infof("[Fluxnetes] Grow response %v \n", gr)
+	return gr, nil
+}
+
+// Shrink releases nodes (and everything under them) from the allocation of a
+// running group. When no nodes are left, the flux job id is cancelled.
+func (fluxion *Fluxion) Shrink(ctx context.Context, in *pb.ShrinkRequest) (*pb.ShrinkResponse, error) {
+
+	fluxion.mutex.Lock()
+	defer fluxion.mutex.Unlock()
+
+	klog.Infof("[Fluxnetes] Received Shrink request %v\n", in)
+	j, err := fluxion.allocatedJob(in.FluxID)
+	if err != nil {
+		return nil, err
+	}
+	kept, left, err := jgf.ReleaseNodes(j.allocated, in.Nodes)
+	if err != nil {
+		return nil, fmt.Errorf("cannot release nodes from flux job id %d: %w", in.FluxID, err)
+	}
+
+	sr := &pb.ShrinkResponse{FluxID: in.FluxID, Nodes: []string{}}
+	if left == 0 {
+		for policy, id := range j.ids {
+			if cli, ok := fluxion.clients[policy]; ok {
+				err = cli.Cancel(int64(id), true)
+				if err != nil {
+					klog.Errorf("[Fluxnetes] Cancel of flux job id %d for policy %q: %s", in.FluxID, policy, err)
+				}
+			}
+		}
+		delete(fluxion.jobs, in.FluxID)
+		sr.Cancelled = true
+	} else {
+		fluxion.reallocate(in.FluxID, kept)
+		for _, result := range utils.ParseAllocResult(kept, "") {
+			sr.Nodes = append(sr.Nodes, result.Basename)
+		}
+	}
+	klog.Infof("[Fluxnetes] Shrink response %v \n", sr)
+	return sr, nil
+}
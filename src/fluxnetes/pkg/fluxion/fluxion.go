@@ -1,52 +1,145 @@
 package fluxion
 
 import (
-	"os"
+	"sync"
 
 	"github.com/converged-computing/fluxnetes/pkg/defaults"
 	pb "github.com/converged-computing/fluxnetes/pkg/fluxion-grpc"
+	"github.com/converged-computing/fluxnetes/pkg/jgf"
 	"github.com/converged-computing/fluxnetes/pkg/jobspec"
 	utils "github.com/converged-computing/fluxnetes/pkg/utils"
-	"github.com/flux-framework/fluxion-go/pkg/fluxcli"
+	"k8s.io/client-go/kubernetes"
 	klog "k8s.io/klog/v2"
 
 	"context"
 	"errors"
+	"fmt"
 )
 
 type Fluxion struct {
-	cli *fluxcli.ReapiClient
 	pb.UnimplementedFluxionServiceServer
+
+	// A fluxion client for each match policy in use, and the default policy
+	clients map[string]reapiClient
+	policy  string
+
+	// Fluxion info does not include the resources, so we keep the
+	// R from each match until the job is cancelled. We also need them
+	// to replay onto the graph when it is rebuilt.
+	jobs         map[uint64]*job
+	nextJobID    uint64
+	nextReplayID uint64
+
+	// The fluxion clients are not safe for concurrent use, and are replaced on a graph update
+	mutex sync.Mutex
+
+	// Needed to rebuild the graph when nodes change, and to make clients
+	clientset    kubernetes.Interface
+	graphOptions utils.GraphOptions
+	encoded      string
+
+	// The vertex name for each node in the graph, for allow and deny lists
+	hostnames map[string]string
+
+	// The number of domains at each topology level, to spread a group across
+	domains map[string]int
+
+	// Walltime (in seconds) for a group without a deadline
+	DefaultDuration int64
+
+	// The name of the scheduler whose pods we allocate. Pods of other schedulers
+	// are subtracted from the graph, and ours are replayed as allocations.
+	SchedulerName string
+
+	// Where the graph is written for GetResources (the default if empty)
+	GraphFile string
 }
 
 // InitFluxion creates a new client to interaction with the fluxion API (via go bindings)
-func (fluxion *Fluxion) InitFluxion(policy, label string) {
-	fluxion.cli = fluxcli.NewReapiClient()
-
-	klog.Infof("[Fluxnetes] Created flux resource client %s", fluxion.cli)
-	err := utils.CreateInClusterJGF(defaults.KubernetesJsonGraphFormat, label)
+// The graph options set the label to skip nodes, the node labels to publish as
+// properties, the topology levels, extended resources and the core, memory and storage units.
+// The graph gets a new registry. An error means the sidecar cannot serve matches.
+func (fluxion *Fluxion) InitFluxion(policy string, graphOptions utils.GraphOptions) error {
+	clientset, err := utils.GetInClusterClientset()
+	if err != nil {
+		return fmt.Errorf("cannot make cluster client: %w", err)
+	}
+	graph, err := fluxion.setup(policy, graphOptions, clientset)
 	if err != nil {
-		return
+		return err
 	}
 
 	// This file needs to be written for GetResources to read later
-	jgf, err := os.ReadFile(defaults.KubernetesJsonGraphFormat)
+	err = graph.WriteJGF(fluxion.GraphFile)
 	if err != nil {
-		klog.Error("Error reading JGF")
-		return
+		return fmt.Errorf("cannot write JGF: %w", err)
 	}
 
-	p := "{}"
-	if policy != "" {
-		p = string("{\"matcher_policy\": \"" + policy + "\"}")
-		klog.Infof("[Fluxnetes] match policy: %s", p)
+	klog.Infof("[Fluxnetes] match policy: %s", policyOptions(policy))
+	_, _, err = fluxion.client(policy)
+	return err
+}
+
+// setup resets fluxion with the graph options (and defaults for the ones not set),
+// and builds the graph from the nodes of the cluster. No client is made yet.
+func (fluxion *Fluxion) setup(policy string, graphOptions utils.GraphOptions, clientset kubernetes.Interface) (*jgf.FluxJGF, error) {
+	fluxion.clients = map[string]reapiClient{}
+	fluxion.policy = policy
+	fluxion.jobs = map[uint64]*job{}
+	fluxion.nextReplayID = replayJobID
+	if fluxion.SchedulerName == "" {
+		fluxion.SchedulerName = defaults.SchedulerName
+	}
+	if fluxion.GraphFile == "" {
+		fluxion.GraphFile = defaults.KubernetesJsonGraphFormat
+	}
+	graphOptions.Registry = jgf.NewRegistry()
+	if len(graphOptions.Topology) == 0 {
+		graphOptions.Topology = utils.DefaultTopology
+	}
+	if len(graphOptions.Resources) == 0 {
+		graphOptions.Resources = utils.DefaultResources
+	}
+	if graphOptions.MemoryUnit <= 0 {
+		graphOptions.MemoryUnit = jgf.DefaultMemoryUnit
+	}
+	if graphOptions.CoreUnit <= 0 {
+		graphOptions.CoreUnit = jgf.DefaultCoreUnit
+	}
+	if graphOptions.StorageUnit <= 0 {
+		graphOptions.StorageUnit = jgf.DefaultStorageUnit
+	}
+	fluxion.graphOptions = graphOptions
+
+	fluxion.clientset = clientset
+	graph, err := utils.BuildJGF(context.Background(), clientset, graphOptions)
+	if err != nil {
+		return nil, fmt.Errorf("cannot build resource graph: %w", err)
+	}
+	fluxion.setGraph(graph)
+
+	encoded, err := graph.ToBytes()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read JGF: %w", err)
+	}
+	fluxion.encoded = string(encoded)
+	return graph, nil
+}
+
+// setGraph saves what we need to know about the graph fluxion has
+func (fluxion *Fluxion) setGraph(graph *jgf.FluxJGF) {
+	fluxion.hostnames = graph.HostNames()
+	fluxion.domains = map[string]int{}
+	for _, level := range fluxion.graphOptions.Topology {
+		fluxion.domains[level.Type] = graph.CountType(level.Type)
 	}
-	fluxion.cli.InitContext(string(jgf), p)
 }
 
-// Destroys properly closes (destroys) the fluxion client handle
+// Destroys properly closes (destroys) the fluxion client handles
 func (fluxion *Fluxion) Close() {
-	fluxion.cli.Destroy()
+	for _, cli := range fluxion.clients {
+		cli.Destroy()
+	}
 }
 
 // Cancel wraps the Cancel function of the fluxion go bindings
@@ -55,19 +148,45 @@ func (fluxion *Fluxion) Cancel(
 	in *pb.CancelRequest,
 ) (*pb.CancelResponse, error) {
 
+	fluxion.mutex.Lock()
+	defer fluxion.mutex.Unlock()
+
 	klog.Infof("[Fluxnetes] received cancel request %v\n", in)
-	err := fluxion.cli.Cancel(int64(in.FluxID), in.NoExistOK)
+	j, err := fluxion.getJob(in.FluxID)
 	if err != nil {
+		// A job that was dropped on a graph update is already gone from fluxion
+		if in.NoExistOK {
+			return &pb.CancelResponse{FluxID: in.FluxID}, nil
+		}
 		return nil, err
 	}
+	cli, id, err := fluxion.jobClient(in.FluxID, j)
+	if err != nil {
+		return nil, err
+	}
+	err = cli.Cancel(int64(id), in.NoExistOK)
+	if err != nil {
+		return nil, err
+	}
+
+	// An allocation or reservation is also in the clients for the other policies
+	for policy, other := range j.ids {
+		if otherCli, ok := fluxion.clients[policy]; ok && otherCli != cli {
+			err = otherCli.Cancel(int64(other), true)
+			if err != nil {
+				klog.Errorf("[Fluxnetes] Cancel of flux job id %d for policy %q: %s", in.FluxID, policy, err)
+			}
+		}
+	}
+	delete(fluxion.jobs, in.FluxID)
 
 	// Why would we have an error code here if we check above?
 	// This (I think) should be an error code for the specific job
 	dr := &pb.CancelResponse{FluxID: in.FluxID}
 	klog.Infof("[Fluxnetes] sending cancel response %v\n", dr)
-	klog.Infof("[Fluxnetes] cancel errors so far: %s\n", fluxion.cli.GetErrMsg())
+	klog.Infof("[Fluxnetes] cancel errors so far: %s\n", cli.GetErrMsg())
 
-	reserved, at, overhead, mode, fluxerr := fluxion.cli.Info(int64(in.FluxID))
+	reserved, at, overhead, mode, fluxerr := cli.Info(int64(id))
 	klog.Infof("\n\t----Job Info output---")
 	klog.Infof("jobid: %d\nreserved: %t\nat: %d\noverhead: %f\nmode: %s\nerror: %d\n", in.FluxID, reserved, at, overhead, mode, fluxerr)
 
@@ -75,31 +194,173 @@ func (fluxion *Fluxion) Cancel(
 	return dr, nil
 }
 
+// Info wraps the Info function of the fluxion go bindings
+// It returns if the job is reserved or allocated, the start time (the ETA
+// for a reservation), and the resources we saved from the match.
+func (fluxion *Fluxion) Info(ctx context.Context, in *pb.InfoRequest) (*pb.InfoResponse, error) {
+
+	fluxion.mutex.Lock()
+	defer fluxion.mutex.Unlock()
+
+	emptyResponse := &pb.InfoResponse{}
+	klog.Infof("[Fluxnetes] Received Info request %v\n", in)
+
+	j, err := fluxion.getJob(in.FluxID)
+	if err != nil {
+		return emptyResponse, err
+	}
+	cli, id, err := fluxion.jobClient(in.FluxID, j)
+	if err != nil {
+		return emptyResponse, err
+	}
+	reserved, at, overhead, mode, fluxerr := cli.Info(int64(id))
+	if fluxerr != nil {
+		klog.Errorf("[Fluxnetes] Info Flux err is %s: %s", fluxerr, cli.GetErrMsg())
+		return emptyResponse, fmt.Errorf("[Fluxnetes] Error in ReapiCliInfo for flux job id %d", in.FluxID)
+	}
+
+	allocated := j.allocated
+	nodes := []string{}
+	if allocated != "" {
+		for _, result := range utils.ParseAllocResult(allocated, "") {
+			nodes = append(nodes, result.Basename)
+		}
+	}
+
+	ir := &pb.InfoResponse{
+		FluxID:    in.FluxID,
+		Reserved:  reserved,
+		Mode:      mode,
+		At:        at,
+		Overhead:  overhead,
+		Allocated: allocated,
+		Nodes:     nodes,
+	}
+	klog.Infof("[Fluxnetes] Info response %v \n", ir)
+	return ir, nil
+}
+
+// Satisfy wraps the MatchSatisfy function of the fluxion go bindings
+// This checks if the request could ever be matched on the resource graph,
+// ignoring what is currently allocated or reserved. A request that is not
+// satisfiable returns satisfiable false with a reason, and an error is only
+// for an issue with Fluxion.
+func (fluxion *Fluxion) Satisfy(ctx context.Context, in *pb.SatisfyRequest) (*pb.SatisfyResponse, error) {
+
+	fluxion.mutex.Lock()
+	defer fluxion.mutex.Unlock()
+
+	emptyResponse := &pb.SatisfyResponse{}
+	klog.Infof("[Fluxnetes] Received Satisfy request %v\n", in)
+
+	// Generate the jobspec, array of bytes converted to string. Nodes that are down
+	// count, since they can come back, so this only checks the capacity of the graph.
+	spec, err := jobspec.CreateJobSpecYaml(in.Podspec, in.Count, jobspec.Options{
+		Constraint:  jobspec.NodeConstraint(in.Podspec, fluxion.graphOptions.Labels),
+		Placement:   fluxion.placement(in.Podspec, in.Count),
+		Resources:   fluxion.extendedResources(in.Podspec),
+		MemoryUnit:  fluxion.graphOptions.MemoryUnit,
+		CoreUnit:    fluxion.graphOptions.CoreUnit,
+		StorageUnit: fluxion.graphOptions.StorageUnit,
+	})
+	if err != nil {
+		return emptyResponse, err
+	}
+
+	// The policy does not change what can be satisfied
+	cli, _, err := fluxion.client("")
+	if err != nil {
+		return emptyResponse, err
+	}
+	satisfiable, overhead, fluxerr := cli.MatchSatisfy(string(spec))
+	klog.Infof("[Fluxnetes] Satisfy for %s: %t (overhead %f)", in.JobName, satisfiable, overhead)
+	if fluxerr != nil {
+		klog.Errorf("[Fluxnetes] Satisfy Flux err is %s: %s", fluxerr, cli.GetErrMsg())
+		return emptyResponse, errors.New("[Fluxnetes] Error in ReapiCliMatchSatisfy")
+	}
+
+	sr := &pb.SatisfyResponse{Satisfiable: satisfiable}
+	if !satisfiable {
+		podspec := in.Podspec
+		sr.Reason = fmt.Sprintf(
+			"%d pods each requesting cpu %dm, memory %d, extended resources %v and storage %d cannot be satisfied by the cluster resources",
+			in.Count, jobspec.CoreCount(podspec, 1), podspec.Memory, podspec.Resources, podspec.Storage,
+		)
+	}
+	klog.Infof("[Fluxnetes] Satisfy response %v \n", sr)
+	return sr, nil
+}
+
 // Match wraps the MatchAllocate function of the fluxion go bindings
 // If a match is not possible, we return an empty response with allocated false
 // This should only return an error if there is some issue with Fluxion
 // or the task of matching.
 func (fluxion *Fluxion) Match(ctx context.Context, in *pb.MatchRequest) (*pb.MatchResponse, error) {
 
+	fluxion.mutex.Lock()
+	defer fluxion.mutex.Unlock()
+	return fluxion.match(in)
+}
+
+// MatchBatch matches an ordered list of groups under one lock, so no other
+// request (or graph update) comes between them. Each is matched like Match, in
+// the order given, and sees what the ones before it allocated or reserved.
+func (fluxion *Fluxion) MatchBatch(ctx context.Context, in *pb.MatchBatchRequest) (*pb.MatchBatchResponse, error) {
+
+	fluxion.mutex.Lock()
+	defer fluxion.mutex.Unlock()
+
+	klog.Infof("[Fluxnetes] Received MatchBatch request with %d groups", len(in.Requests))
+	br := &pb.MatchBatchResponse{}
+	for _, request := range in.Requests {
+		item := &pb.MatchBatchItem{}
+		response, err := fluxion.match(request)
+		if err != nil {
+			klog.Errorf("[Fluxnetes] MatchBatch error for %s: %s", request.JobName, err)
+			item.Error = err.Error()
+			response = &pb.MatchResponse{}
+		}
+		item.Response = response
+		br.Items = append(br.Items, item)
+	}
+	return br, nil
+}
+
+// match does a match for Match or MatchBatch, which hold the lock
+func (fluxion *Fluxion) match(in *pb.MatchRequest) (*pb.MatchResponse, error) {
 	emptyResponse := &pb.MatchResponse{}
 
 	// Prepare an empty match response (that can still be serialized)
 	klog.Infof("[Fluxnetes] Received Match request %v\n", in)
 
+	// Nodes the Kubernetes filter plugins rule out are not considered
+	hostConstraint, possible := fluxion.nodeConstraint(in.AllowNodes, in.DenyNodes)
+	if !possible {
+		// This is not the same as unsatisfiable: the nodes may not be in the graph
+		// yet, so the error has the client ask again
+		return emptyResponse, fmt.Errorf("none of the allowed nodes for %s are in the graph", in.JobName)
+	}
+
 	// Generate the jobspec, array of bytes converted to string
-	spec, err := jobspec.CreateJobSpecYaml(in.Podspec, in.Count)
+	spec, err := fluxion.matchSpec(in.Podspec, in.Count, in.Duration, hostConstraint)
+	if err != nil {
+		return emptyResponse, err
+	}
+
+	// The group can ask for a match policy other than the default
+	cli, policy, err := fluxion.client(in.Podspec.Policy)
 	if err != nil {
 		return emptyResponse, err
 	}
 
 	// Ask flux to match allocate, either with or without a reservation
-	reserved, allocated, at, overhead, jobid, fluxerr := fluxion.cli.MatchAllocate(in.Reserve, string(spec))
+	reserved, allocated, at, overhead, jobid, fluxerr := cli.MatchAllocate(in.Reserve, string(spec))
 	utils.PrintOutput(reserved, allocated, at, overhead, jobid, fluxerr)
 
 	// Be explicit about errors (or not)
 	// These errors are related to matching, not whether it is possible or not,
 	// and should not happen.
-	errorMessages := fluxion.cli.GetErrMsg()
+	errorMessages := cli.GetErrMsg()
 	if errorMessages == "" {
 		klog.Info("[Fluxnetes] There are no errors")
 	} else {
@@ -114,23 +375,26 @@ func (fluxion *Fluxion) Match(ctx context.Context, in *pb.MatchRequest) (*pb.Mat
 	// handle this information how they see fit. If we can allocate, we return
 	// the nodes.
 	nodelist := []*pb.NodeAlloc{}
-	haveAllocation := allocated != ""
+	haveAllocation := allocated != "" && !reserved
+
+	// Save the resources (allocated or reserved), and give the client our id for the job
+	var fluxID uint64
+	if haveAllocation || reserved {
+		fluxID = fluxion.addJob(policy, jobid, allocated, reserved)
+	}
+
+	// The clients for other policies need to know the resources are allocated (or held)
+	if haveAllocation || reserved {
+		fluxion.mirror(fluxID)
+	}
 
 	if haveAllocation {
-		// Pass the job name (the group) for inspection/ordering later
-		nodetasks := utils.ParseAllocResult(allocated, in.JobName)
-		nodelist = make([]*pb.NodeAlloc, len(nodetasks))
-		for i, result := range nodetasks {
-			nodelist[i] = &pb.NodeAlloc{
-				NodeID: result.Basename,
-				Tasks:  int32(result.CoreCount) / in.Podspec.Cpu,
-			}
-		}
+		nodelist = fluxion.nodelist(allocated, in.JobName, in.Podspec)
 	}
 
 	mr := &pb.MatchResponse{
 		Nodelist:   nodelist,
-		FluxID:     uint64(jobid),
+		FluxID:     fluxID,
 		Reserved:   reserved,
 		ReservedAt: at,
 		Allocated:  haveAllocation,
@@ -138,3 +402,123 @@ func (fluxion *Fluxion) Match(ctx context.Context, in *pb.MatchRequest) (*pb.Mat
 	klog.Infof("[Fluxnetes] Match response %v \n", mr)
 	return mr, nil
 }
+
+// matchSpec generates the jobspec to match count pods of a group. Only nodes that
+// are up, with the labels the pod selects (and the host constraint allows) are matched.
+func (fluxion *Fluxion) matchSpec(podspec *pb.PodSpec, count int32, duration int64, hostConstraint *jobspec.Constraint) ([]byte, error) {
+	constraint := jobspec.All(jobspec.ExcludeDown, jobspec.NodeConstraint(podspec, fluxion.graphOptions.Labels), hostConstraint)
+	return jobspec.CreateJobSpecYaml(podspec, count, jobspec.Options{
+		Constraint:  constraint,
+		Duration:    fluxion.duration(duration),
+		Placement:   fluxion.placement(podspec, count),
+		Resources:   fluxion.extendedResources(podspec),
+		MemoryUnit:  fluxion.graphOptions.MemoryUnit,
+		CoreUnit:    fluxion.graphOptions.CoreUnit,
+		StorageUnit: fluxion.graphOptions.StorageUnit,
+	})
+}
+
+// nodelist returns the nodes in an allocation, with the number of pods for each.
+// The job name (the group) is passed for inspection/ordering later.
+func (fluxion *Fluxion) nodelist(allocated, jobName string, podspec *pb.PodSpec) []*pb.NodeAlloc {
+	nodetasks := utils.ParseAllocResult(allocated, jobName)
+	nodelist := make([]*pb.NodeAlloc, len(nodetasks))
+	for i, result := range nodetasks {
+		nodelist[i] = &pb.NodeAlloc{
+			NodeID: result.Basename,
+			Tasks:  int32(int64(result.CoreCount) / jobspec.CoreCount(podspec, fluxion.graphOptions.CoreUnit)),
+		}
+
+		// An exclusive group has one pod on each node
+		if podspec.Exclusive {
+			nodelist[i].Tasks = 1
+		}
+	}
+	return nodelist
+}
+
+// nodeConstraint returns a hostlist constraint for an allow list, or for the
+// negation of a deny list, and nil if there are neither. It returns false if
+// none of the allowed nodes are in the graph, so nothing can match.
+func (fluxion *Fluxion) nodeConstraint(allow, deny []string) (*jobspec.Constraint, bool) {
+	hostnames := func(nodes []string) []string {
+		names := []string{}
+		for _, node := range nodes {
+			name, ok := fluxion.hostnames[node]
+			if ok {
+				names = append(names, name)
+			}
+		}
+		return names
+	}
+	if len(allow) > 0 {
+		names := hostnames(allow)
+		if len(names) == 0 {
+			return nil, false
+		}
+		return &jobspec.Constraint{Hostlist: names}, true
+	}
+	names := hostnames(deny)
+	if len(names) == 0 {
+		return nil, true
+	}
+	return &jobspec.Constraint{Not: []*jobspec.Constraint{{Hostlist: names}}}, true
+}
+
+// duration returns the walltime of a group, or the default if it has no deadline
+func (fluxion *Fluxion) duration(seconds int64) int64 {
+	if seconds > 0 {
+		return seconds
+	}
+	if fluxion.DefaultDuration > 0 {
+		return fluxion.DefaultDuration
+	}
+	return defaults.Duration
+}
+
+// placement returns where to put a group in the topology, if it asks to be packed
+// into one domain or spread across domains of a level. A level that is not in
+// the graph is ignored.
+func (fluxion *Fluxion) placement(spec *pb.PodSpec, count int32) *jobspec.Placement {
+	if spec.Pack != "" {
+		if _, ok := fluxion.domains[spec.Pack]; ok {
+			return jobspec.PackPlacement(spec.Pack)
+		}
+		klog.Infof("[Fluxnetes] Cannot pack %s, %s is not a topology level", spec.Id, spec.Pack)
+	}
+	if spec.Spread != "" {
+		domains, ok := fluxion.domains[spec.Spread]
+		if ok {
+			placement := jobspec.SpreadPlacement(spec.Spread, count, domains)
+			if placement == nil {
+				klog.Infof("[Fluxnetes] Cannot spread %d pods of %s evenly over %d domains of %s, not placing it", count, spec.Id, domains, spec.Spread)
+			}
+			return placement
+		}
+		klog.Infof("[Fluxnetes] Cannot spread %s, %s is not a topology level", spec.Id, spec.Spread)
+	}
+	return nil
+}
+
+// extendedResources returns the extended resources a pod needs in each slot, in
+// units of the vertices in the graph. A resource that is not in the graph cannot
+// be accounted for, and is left to the kubelet.
+func (fluxion *Fluxion) extendedResources(spec *pb.PodSpec) []jobspec.Resource {
+	resources := []jobspec.Resource{}
+	known := map[string]bool{}
+	for _, extended := range fluxion.graphOptions.Resources {
+		known[extended.Name] = true
+		request := spec.Resources[extended.Name]
+		if request <= 0 {
+			continue
+		}
+		count := (request + extended.Unit - 1) / extended.Unit
+		resources = append(resources, jobspec.Resource{Type: extended.Type, Count: count})
+	}
+	for name := range spec.Resources {
+		if !known[name] {
+			klog.Infof("[Fluxnetes] Extended resource %s of %s is not in the graph", name, spec.Id)
+		}
+	}
+	return resources
+}
@@ -0,0 +1,69 @@
+package fluxion
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/converged-computing/fluxnetes/pkg/jgf"
+	utils "github.com/converged-computing/fluxnetes/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestUpdateGraphAfterRestart(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-a"},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("2"),
+				corev1.ResourceMemory: resource.MustParse("2Gi"),
+			},
+			Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+			},
+		},
+	}
+
+	// A pod we bound before the restart, which fluxion has no allocation for
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "ours", Namespace: "default"},
+		Spec: corev1.PodSpec{
+			NodeName:      "node-a",
+			SchedulerName: "fluxnetes",
+			Containers: []corev1.Container{{
+				Name: "c",
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+				},
+			}},
+		},
+	}
+	fluxion := &Fluxion{SchedulerName: "fluxnetes", GraphFile: filepath.Join(t.TempDir(), "graph.json")}
+	_, err := fluxion.setup("", utils.GraphOptions{}, fake.NewSimpleClientset(node, pod))
+	assert.Nil(t, err)
+	_, _, err = fluxion.client("")
+	assert.Nil(t, err)
+	t.Cleanup(fluxion.Close)
+
+	cores := func() int {
+		var graph jgf.FluxJGF
+		assert.Nil(t, json.Unmarshal([]byte(fluxion.encoded), &graph))
+		count := 0
+		for _, vertex := range graph.Graph.Nodes {
+			if vertex.Metadata.Type == jgf.CoreType {
+				count += 1
+			}
+		}
+		return count
+	}
+	assert.Equal(t, 1, cores())
+
+	// The rebuild still takes the core of the pod away
+	assert.Nil(t, fluxion.UpdateGraph(context.Background()))
+	assert.Equal(t, 1, cores())
+}
@@ -0,0 +1,81 @@
+package fluxion
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Fluxion gives out job ids from a counter that starts again with a new client.
+// When the graph is rebuilt, existing allocations are replayed with ids from
+// this range, so they cannot collide with ids for new matches.
+const replayJobID = uint64(1) << 32
+
+// job is a match (allocation or reservation) we know about. The id fluxion
+// has for it can change when the graph is rebuilt, so clients are only given
+// the id we assign, which stays the same.
+type job struct {
+	// The match policy of the fluxion client that made the match
+	policy string
+
+	// The fluxion job id in the client for each policy. An allocation or a
+	// reservation is in all of the clients.
+	ids map[string]uint64
+
+	// The resources (R) from the match
+	allocated string
+	reserved  bool
+}
+
+// kind returns what the job is, for logs
+func (j *job) kind() string {
+	if j.reserved {
+		return "reservation"
+	}
+	return "allocation"
+}
+
+// addJob saves a match by the client for a policy and returns the id for the client
+func (fluxion *Fluxion) addJob(policy string, id uint64, allocated string, reserved bool) uint64 {
+	fluxID := fluxion.nextJobID
+	fluxion.nextJobID += 1
+	fluxion.jobs[fluxID] = &job{
+		policy:    policy,
+		ids:       map[string]uint64{policy: id},
+		allocated: allocated,
+		reserved:  reserved,
+	}
+	return fluxID
+}
+
+// jobIDs returns the flux job ids we know about, in order, so allocations and
+// reservations are replayed the same way each time
+func (fluxion *Fluxion) jobIDs() []uint64 {
+	ids := []uint64{}
+	for fluxID := range fluxion.jobs {
+		ids = append(ids, fluxID)
+	}
+	sort.Slice(ids, func(i, k int) bool { return ids[i] < ids[k] })
+	return ids
+}
+
+// getJob returns a job by the id we gave the client
+func (fluxion *Fluxion) getJob(fluxID uint64) (*job, error) {
+	j, ok := fluxion.jobs[fluxID]
+	if !ok {
+		return nil, fmt.Errorf("flux job id %d does not exist", fluxID)
+	}
+	return j, nil
+}
+
+// jobClient returns the fluxion client that has a job, and the id it has for it.
+// This is the client that made the match if it still has it, or the default.
+func (fluxion *Fluxion) jobClient(fluxID uint64, j *job) (reapiClient, uint64, error) {
+	for _, policy := range []string{j.policy, fluxion.policy} {
+		cli, ok := fluxion.clients[policy]
+		id, known := j.ids[policy]
+		if ok && known {
+			return cli, id, nil
+		}
+	}
+	return nil, 0, fmt.Errorf("flux job id %d is not in fluxion", fluxID)
+}
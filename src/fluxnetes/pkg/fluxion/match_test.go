@@ -0,0 +1,94 @@
+//go:build fluxion
+
+package fluxion
+
+// These tests match with fluxion, so they need the fluxion bindings and are run
+// with the fluxion build tag (make test).
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/converged-computing/fluxnetes/pkg/fluxion-grpc"
+	utils "github.com/converged-computing/fluxnetes/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// newTestFluxion returns fluxion with a graph of one ready node with 2 cores,
+// and fails the test if fluxion cannot match a group that takes the node
+func newTestFluxion(t *testing.T) (*Fluxion, *pb.PodSpec) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-a"},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("2"),
+				corev1.ResourceMemory: resource.MustParse("2Gi"),
+			},
+			Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+			},
+		},
+	}
+	fluxion := &Fluxion{}
+	_, err := fluxion.setup("", utils.GraphOptions{}, fake.NewSimpleClientset(node))
+	assert.Nil(t, err)
+	_, _, err = fluxion.client("")
+	assert.Nil(t, err)
+	t.Cleanup(fluxion.Close)
+
+	podspec := &pb.PodSpec{Cpu: 2}
+	sr, err := fluxion.Satisfy(context.Background(), &pb.SatisfyRequest{Podspec: podspec, Count: 1, JobName: "check"})
+	assert.Nil(t, err)
+	if !sr.Satisfiable {
+		t.Fatal("fluxion cannot match the test graph")
+	}
+	return fluxion, podspec
+}
+
+func TestMatchBatchOrder(t *testing.T) {
+	fluxion, podspec := newTestFluxion(t)
+
+	// Each group takes the whole node for a minute
+	request := func(name string, reserve bool) *pb.MatchRequest {
+		return &pb.MatchRequest{Podspec: podspec, Count: 1, JobName: name, Reserve: reserve, Duration: 60}
+	}
+	response, err := fluxion.MatchBatch(context.Background(), &pb.MatchBatchRequest{
+		Requests: []*pb.MatchRequest{
+			request("first", true),
+			request("second", true),
+			request("third", false),
+			request("fourth", true),
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(response.Items))
+	for _, item := range response.Items {
+		assert.Equal(t, "", item.Error)
+	}
+	first := response.Items[0].Response
+	second := response.Items[1].Response
+	third := response.Items[2].Response
+	fourth := response.Items[3].Response
+
+	// The first group gets the node
+	assert.True(t, first.Allocated)
+	assert.Equal(t, 1, len(first.Nodelist))
+	assert.Equal(t, "node-a", first.Nodelist[0].NodeID)
+
+	// The second sees the allocation, and is reserved for when it ends
+	assert.False(t, second.Allocated)
+	assert.True(t, second.Reserved)
+	assert.True(t, second.ReservedAt > 0)
+
+	// The third cannot be matched now, and did not ask for a reservation
+	assert.False(t, third.Allocated)
+	assert.False(t, third.Reserved)
+
+	// The fourth sees the reservation of the second, and is reserved after it
+	assert.True(t, fourth.Reserved)
+	assert.True(t, fourth.ReservedAt > second.ReservedAt)
+}
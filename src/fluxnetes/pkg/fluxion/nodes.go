@@ -0,0 +1,259 @@
+package fluxion
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/converged-computing/fluxnetes/pkg/jgf"
+	utils "github.com/converged-computing/fluxnetes/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+	klog "k8s.io/klog/v2"
+)
+
+// The cluster watcher keeps the fluxion resource graph in sync with the cluster.
+// The go bindings cannot add or remove vertices in place, so a change rebuilds
+// the graph with the same vertex ids (from the registry), loads it into a new
+// client, and replays the allocations and reservations we know about onto it.
+
+const (
+	// How long to wait after a node or pod event, so a burst of changes is one rebuild
+	nodeEventDelay = 2 * time.Second
+
+	// The shortest time between rebuilds. Pods from other schedulers come and go
+	// all of the time, and each rebuild holds the lock (so no matches are made)
+	// while the graph is built and the allocations and reservations are replayed.
+	minRebuildInterval = 10 * time.Second
+)
+
+// nodeSummary is what the graph uses from a node. An update that does not
+// change it (e.g., a status heartbeat) does not rebuild the graph.
+type nodeSummary struct {
+	skip       bool
+	down       bool
+	topology   string
+	cpu        string
+	memory     string
+	storage    string
+	extended   string
+	properties string
+}
+
+// summarizeNode returns the parts of a node that the graph is built from
+func summarizeNode(node *corev1.Node, options utils.GraphOptions) nodeSummary {
+	label := options.SkipLabel
+	_, skip := node.Labels[label]
+
+	// The domain of the node at each topology level
+	domains := []string{}
+	for _, level := range options.Topology {
+		domains = append(domains, node.Labels[level.Label])
+	}
+
+	// Extended resources in the graph, in the order they are configured
+	extended := []string{}
+	for _, resource := range options.Resources {
+		quantity := node.Status.Allocatable[corev1.ResourceName(resource.Name)]
+		extended = append(extended, quantity.String())
+	}
+
+	// Properties (labels and taints) are compared in a stable order
+	properties := []string{}
+	for key, value := range utils.NodeProperties(node, options.Labels) {
+		properties = append(properties, key+"\t"+value)
+	}
+	sort.Strings(properties)
+
+	return nodeSummary{
+		skip:       utils.IsControlPlane(node) || (label != "" && skip),
+		down:       utils.IsNodeDown(node),
+		topology:   strings.Join(domains, "\n"),
+		cpu:        node.Status.Allocatable.Cpu().String(),
+		memory:     node.Status.Allocatable.Memory().String(),
+		storage:    node.Status.Allocatable.StorageEphemeral().String(),
+		extended:   strings.Join(extended, "\n"),
+		properties: strings.Join(properties, "\n"),
+	}
+}
+
+// NodeChanged returns true if a node update changes the resource graph
+func NodeChanged(oldNode, newNode *corev1.Node, options utils.GraphOptions) bool {
+	return summarizeNode(oldNode, options) != summarizeNode(newNode, options)
+}
+
+// WatchCluster rebuilds the resource graph when nodes are added, removed,
+// cordoned or uncordoned, become ready or not ready, change resources,
+// topology domain, or published labels and taints,
+// and when pods from other schedulers start or stop using node resources.
+// It runs until the context is done.
+func (fluxion *Fluxion) WatchCluster(ctx context.Context) {
+	if fluxion.clientset == nil {
+		klog.Error("[Fluxnetes] No cluster client, cannot watch nodes")
+		return
+	}
+
+	// A full channel means a rebuild is already waiting
+	rebuild := make(chan struct{}, 1)
+	trigger := func() {
+		select {
+		case rebuild <- struct{}{}:
+		default:
+		}
+	}
+
+	factory := informers.NewSharedInformerFactory(fluxion.clientset, 0)
+	fluxion.watchNodes(factory, trigger)
+	fluxion.watchPods(factory, trigger)
+	factory.Start(ctx.Done())
+	factory.WaitForCacheSync(ctx.Done())
+
+	// The initial list adds every node and pod, and the graph was just built from them
+	select {
+	case <-rebuild:
+	default:
+	}
+
+	// Changes that come in while we wait are in the same rebuild
+	last := time.Time{}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-rebuild:
+			wait := nodeEventDelay
+			if next := time.Until(last.Add(minRebuildInterval)); next > wait {
+				wait = next
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+			select {
+			case <-rebuild:
+			default:
+			}
+			err := fluxion.UpdateGraph(ctx)
+			if err != nil {
+				klog.Errorf("[Fluxnetes] Error updating resource graph: %s", err)
+			}
+			last = time.Now()
+		}
+	}
+}
+
+// watchNodes triggers a rebuild for node changes that change the graph
+func (fluxion *Fluxion) watchNodes(factory informers.SharedInformerFactory, trigger func()) {
+	changed := func(reason string, node *corev1.Node) {
+		klog.Infof("[Fluxnetes] Node %s %s", node.Name, reason)
+		trigger()
+	}
+	informer := factory.Core().V1().Nodes().Informer()
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			changed("added", obj.(*corev1.Node))
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldNode := oldObj.(*corev1.Node)
+			newNode := newObj.(*corev1.Node)
+			if NodeChanged(oldNode, newNode, fluxion.graphOptions) {
+				changed("changed", newNode)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			node, ok := obj.(*corev1.Node)
+			if !ok {
+				tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+				if !ok {
+					return
+				}
+				node, ok = tombstone.Obj.(*corev1.Node)
+				if !ok {
+					return
+				}
+			}
+			changed("removed", node)
+		},
+	})
+}
+
+// UpdateGraph rebuilds the resource graph from the current nodes, and replaces
+// the fluxion clients with one (for the default policy) that has it. Allocations
+// and reservations are replayed so they stay valid, and one that no longer fits
+// (e.g., its node was removed) is dropped and logged. A dropped reservation is
+// asked for again when the scheduler retries its group, which it does for every
+// group that is reserved and not allocated. Clients keep using the same flux job
+// ids for the allocations and reservations that are kept.
+func (fluxion *Fluxion) UpdateGraph(ctx context.Context) error {
+	fluxion.mutex.Lock()
+	defer fluxion.mutex.Unlock()
+
+	// Vertices that are allocated are kept, even if pods from other schedulers
+	// now ask for more than the node has left
+	allocated := map[string]bool{}
+	for fluxID, j := range fluxion.jobs {
+		if j.reserved {
+			continue
+		}
+		paths, err := jgf.AllocatedPaths(j.allocated)
+		if err != nil {
+			klog.Errorf("[Fluxnetes] Error reading allocation for flux job id %d: %s", fluxID, err)
+			continue
+		}
+		for path := range paths {
+			allocated[path] = true
+		}
+	}
+
+	// Pods we scheduled are accounted for by the allocations we replay, and the
+	// ones we do not know about (e.g., after a restart) by their requests
+	options := fluxion.graphOptions
+	options.OwnScheduler = fluxion.SchedulerName
+	options.Allocated = allocated
+	graph, err := utils.BuildJGF(ctx, fluxion.clientset, options)
+	if err != nil {
+		return err
+	}
+	encoded, err := graph.ToBytes()
+	if err != nil {
+		return err
+	}
+
+	// Only the client for the default policy is made now, and the others
+	// are made (with the allocations) when a group asks for them again
+	cli, ids, err := fluxion.newClient(string(encoded), fluxion.policy)
+	if err != nil {
+		return err
+	}
+	reservations := 0
+	for fluxID, j := range fluxion.jobs {
+		id, ok := ids[fluxID]
+		if !ok {
+			klog.Errorf("[Fluxnetes] Dropped %s for flux job id %d, which does not fit the updated graph", j.kind(), fluxID)
+			delete(fluxion.jobs, fluxID)
+			continue
+		}
+		j.ids = map[string]uint64{fluxion.policy: id}
+		if j.reserved {
+			reservations += 1
+		}
+	}
+
+	// Keep the written graph the same as what fluxion has, for GetResources
+	err = graph.WriteJGF(fluxion.GraphFile)
+	if err != nil {
+		klog.Errorf("[Fluxnetes] Error writing updated JGF: %s", err)
+	}
+	for _, old := range fluxion.clients {
+		old.Destroy()
+	}
+	fluxion.clients = map[string]reapiClient{fluxion.policy: cli}
+	fluxion.encoded = string(encoded)
+	fluxion.setGraph(graph)
+	klog.Infof("[Fluxnetes] Resource graph updated with %d vertices, %d allocations and %d reservations kept",
+		len(graph.Graph.Nodes), len(fluxion.jobs)-reservations, reservations)
+	return nil
+}
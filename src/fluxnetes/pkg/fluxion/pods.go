@@ -0,0 +1,78 @@
+package fluxion
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+	klog "k8s.io/klog/v2"
+)
+
+// Pods placed by other schedulers (the default scheduler, daemonsets, static pods)
+// use node resources that fluxion does not allocate. The graph subtracts their
+// requests when it is built, so a pod from another scheduler that is bound to a
+// node, or that finishes or is deleted, rebuilds the graph to reserve or release
+// what it asked for. Pods of the scheduler named by SchedulerName are ours, and
+// are accounted for by allocations. A rebuild holds the lock while the clients
+// are made again, so rebuilds are coalesced (see WatchCluster).
+
+// podUsesNode returns true if the pod takes resources from a node fluxion
+// does not know about: it is bound, not finished, and not scheduled by us.
+func podUsesNode(pod *corev1.Pod, schedulerName string) bool {
+	if pod.Spec.SchedulerName == schedulerName || pod.Spec.NodeName == "" {
+		return false
+	}
+	return pod.Status.Phase != corev1.PodSucceeded && pod.Status.Phase != corev1.PodFailed
+}
+
+// PodChanged returns true if a pod update changes what is used on a node
+func PodChanged(oldPod, newPod *corev1.Pod, schedulerName string) bool {
+	if podUsesNode(oldPod, schedulerName) != podUsesNode(newPod, schedulerName) {
+		return true
+	}
+	return podUsesNode(newPod, schedulerName) && oldPod.Spec.NodeName != newPod.Spec.NodeName
+}
+
+// watchPods triggers a rebuild when a pod from another scheduler starts or stops using a node
+func (fluxion *Fluxion) watchPods(factory informers.SharedInformerFactory, trigger func()) {
+	changed := func(reason string, pod *corev1.Pod) {
+		klog.Infof("[Fluxnetes] Pod %s/%s from %s %s node %s", pod.Namespace, pod.Name, pod.Spec.SchedulerName, reason, pod.Spec.NodeName)
+		trigger()
+	}
+	informer := factory.Core().V1().Pods().Informer()
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			pod := obj.(*corev1.Pod)
+			if podUsesNode(pod, fluxion.SchedulerName) {
+				changed("is using", pod)
+			}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldPod := oldObj.(*corev1.Pod)
+			newPod := newObj.(*corev1.Pod)
+			if !PodChanged(oldPod, newPod, fluxion.SchedulerName) {
+				return
+			}
+			if podUsesNode(newPod, fluxion.SchedulerName) {
+				changed("is using", newPod)
+			} else {
+				changed("released", oldPod)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			pod, ok := obj.(*corev1.Pod)
+			if !ok {
+				tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+				if !ok {
+					return
+				}
+				pod, ok = tombstone.Obj.(*corev1.Pod)
+				if !ok {
+					return
+				}
+			}
+			if podUsesNode(pod, fluxion.SchedulerName) {
+				changed("released", pod)
+			}
+		},
+	})
+}
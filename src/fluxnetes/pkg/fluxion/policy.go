@@ -0,0 +1,199 @@
+package fluxion
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	pb "github.com/converged-computing/fluxnetes/pkg/fluxion-grpc"
+	"github.com/flux-framework/fluxion-go/pkg/fluxcli"
+	klog "k8s.io/klog/v2"
+)
+
+// The match policy is set when a fluxion client is made, so each policy that
+// groups ask for has a client of its own. The clients have the same graph, and
+// an allocation or reservation made by one is added to the others, so they agree
+// on what is free now and what is held for later. A reservation is added with the
+// start time in its resources (R), so it holds the same resources in each client.
+
+// reapiClient is what we use of a fluxion client, so tests can give another
+type reapiClient interface {
+	InitContext(jgf string, options string) error
+	MatchAllocate(orelseReserve bool, jobspec string) (bool, string, int64, float64, uint64, error)
+	MatchSatisfy(jobspec string) (bool, float64, error)
+	UpdateAllocate(jobid int, r string) (int64, float64, string, error)
+	Cancel(jobid int64, noentOK bool) error
+	Info(jobid int64) (bool, int64, float64, string, error)
+	GetErrMsg() string
+	Destroy()
+}
+
+// newReapiClient makes a client with the fluxion go bindings
+var newReapiClient = func() reapiClient {
+	return fluxcli.NewReapiClient()
+}
+
+// policyOptions returns the fluxion options for a match policy (empty is the fluxion default)
+func policyOptions(policy string) string {
+	if policy == "" {
+		return "{}"
+	}
+	return "{\"matcher_policy\": \"" + policy + "\"}"
+}
+
+// newClient makes a fluxion client for a match policy with the graph, and replays
+// the allocations and reservations we have onto it, in the order of their flux job
+// ids. It returns the id the client has for each, and one that does not fit the
+// graph is left out.
+func (fluxion *Fluxion) newClient(encoded, policy string) (reapiClient, map[uint64]uint64, error) {
+	cli := newReapiClient()
+	err := cli.InitContext(encoded, policyOptions(policy))
+	if err != nil {
+		cli.Destroy()
+		return nil, nil, err
+	}
+	klog.Infof("[Fluxnetes] Created flux resource client for match policy %q", policy)
+
+	ids := map[uint64]uint64{}
+	for _, fluxID := range fluxion.jobIDs() {
+		j := fluxion.jobs[fluxID]
+		if j.allocated == "" {
+			continue
+		}
+		id := fluxion.nextReplayID
+		fluxion.nextReplayID += 1
+		_, _, _, err := cli.UpdateAllocate(int(id), j.allocated)
+		if err != nil {
+			klog.Errorf("[Fluxnetes] %s for flux job id %d does not fit the graph for policy %q: %s", j.kind(), fluxID, policy, cli.GetErrMsg())
+			continue
+		}
+		ids[fluxID] = id
+	}
+	return cli, ids, nil
+}
+
+// addClient saves the client for a policy, with the ids it has for allocations and reservations
+func (fluxion *Fluxion) addClient(policy string, cli reapiClient, ids map[uint64]uint64) {
+	for fluxID, id := range ids {
+		fluxion.jobs[fluxID].ids[policy] = id
+	}
+	fluxion.clients[policy] = cli
+}
+
+// dropClient destroys the client for a policy. Allocations and reservations are
+// kept by the other clients, and replayed if the client is made again.
+func (fluxion *Fluxion) dropClient(policy string) {
+	cli, ok := fluxion.clients[policy]
+	if !ok {
+		return
+	}
+	cli.Destroy()
+	delete(fluxion.clients, policy)
+	for _, j := range fluxion.jobs {
+		delete(j.ids, policy)
+	}
+}
+
+// policies returns the policies we have clients for, in order
+func (fluxion *Fluxion) policies() []string {
+	policies := []string{}
+	for policy := range fluxion.clients {
+		policies = append(policies, policy)
+	}
+	sort.Strings(policies)
+	return policies
+}
+
+// dropOrphans drops the allocations and reservations that no client has, e.g.,
+// because one did not fit a client that was made again. Each is logged, and the
+// flux job id is gone from fluxion, so a cancel for it is a no-op.
+func (fluxion *Fluxion) dropOrphans() {
+	for _, fluxID := range fluxion.jobIDs() {
+		j := fluxion.jobs[fluxID]
+		if len(j.ids) == 0 {
+			klog.Errorf("[Fluxnetes] Dropped %s for flux job id %d, which no fluxion client has", j.kind(), fluxID)
+			delete(fluxion.jobs, fluxID)
+		}
+	}
+}
+
+// client returns the fluxion client for the match policy a group asks for, and
+// makes it if the group is the first to ask. An empty policy is the default, and
+// the default is also used (and returned) for a policy fluxion does not accept.
+func (fluxion *Fluxion) client(policy string) (reapiClient, string, error) {
+	if policy == "" {
+		policy = fluxion.policy
+	}
+	cli, ok := fluxion.clients[policy]
+	if ok {
+		return cli, policy, nil
+	}
+	cli, ids, err := fluxion.newClient(fluxion.encoded, policy)
+	if err == nil {
+		fluxion.addClient(policy, cli, ids)
+		return cli, policy, nil
+	}
+	if policy == fluxion.policy {
+		return nil, policy, fmt.Errorf("cannot make a client for the default match policy %q: %w", policy, err)
+	}
+	klog.Errorf("[Fluxnetes] Cannot use match policy %q, using %q: %s", policy, fluxion.policy, err)
+	return fluxion.client(fluxion.policy)
+}
+
+// mirror adds an allocation or reservation made by the client for one policy to
+// the other clients. A client that cannot take it (e.g., the resources are taken
+// there by a match it was given before) is made again, and the default client is
+// made again right away.
+func (fluxion *Fluxion) mirror(fluxID uint64) {
+	j := fluxion.jobs[fluxID]
+	if j.allocated == "" {
+		return
+	}
+	for _, policy := range fluxion.policies() {
+		if policy == j.policy {
+			continue
+		}
+		cli := fluxion.clients[policy]
+		id := fluxion.nextReplayID
+		fluxion.nextReplayID += 1
+		_, _, _, err := cli.UpdateAllocate(int(id), j.allocated)
+		if err == nil {
+			j.ids[policy] = id
+			continue
+		}
+		klog.Errorf("[Fluxnetes] Cannot add flux job id %d to the client for policy %q, making it again: %s", fluxID, policy, cli.GetErrMsg())
+		fluxion.dropClient(policy)
+		if policy == fluxion.policy {
+			_, _, err := fluxion.client(policy)
+			if err != nil {
+				klog.Errorf("[Fluxnetes] %s", err)
+			}
+		}
+	}
+	fluxion.dropOrphans()
+}
+
+// SetPolicy reinitializes fluxion with a new default match policy. The client is
+// made again from the graph and allocations and reservations are replayed onto it,
+// so they are kept.
+// Groups that ask for a policy of their own still use it.
+func (fluxion *Fluxion) SetPolicy(ctx context.Context, in *pb.SetPolicyRequest) (*pb.SetPolicyResponse, error) {
+
+	fluxion.mutex.Lock()
+	defer fluxion.mutex.Unlock()
+
+	klog.Infof("[Fluxnetes] Received SetPolicy request %v\n", in)
+	cli, ids, err := fluxion.newClient(fluxion.encoded, in.Policy)
+	if err != nil {
+		return nil, fmt.Errorf("cannot use match policy %q: %w", in.Policy, err)
+	}
+	previous := fluxion.policy
+	fluxion.dropClient(in.Policy)
+	fluxion.addClient(in.Policy, cli, ids)
+	fluxion.policy = in.Policy
+	fluxion.dropOrphans()
+
+	sr := &pb.SetPolicyResponse{Previous: previous, Policy: in.Policy}
+	klog.Infof("[Fluxnetes] SetPolicy response %v \n", sr)
+	return sr, nil
+}
@@ -0,0 +1,120 @@
+package fluxion
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	pb "github.com/converged-computing/fluxnetes/pkg/fluxion-grpc"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClient takes any resources (R) that are not rejected, and keeps them in order
+type fakeClient struct {
+	reject    map[string]bool
+	allocated []string
+	destroyed bool
+}
+
+func (c *fakeClient) InitContext(jgf string, options string) error { return nil }
+func (c *fakeClient) MatchAllocate(orelseReserve bool, jobspec string) (bool, string, int64, float64, uint64, error) {
+	return false, "", 0, 0, 0, nil
+}
+func (c *fakeClient) MatchSatisfy(jobspec string) (bool, float64, error) { return true, 0, nil }
+func (c *fakeClient) UpdateAllocate(jobid int, r string) (int64, float64, string, error) {
+	if c.reject[r] {
+		return 0, 0, "", fmt.Errorf("cannot take %s", r)
+	}
+	c.allocated = append(c.allocated, r)
+	return 0, 0, r, nil
+}
+func (c *fakeClient) Cancel(jobid int64, noentOK bool) error { return nil }
+func (c *fakeClient) Info(jobid int64) (bool, int64, float64, string, error) {
+	return false, 0, 0, "", nil
+}
+func (c *fakeClient) GetErrMsg() string { return "" }
+func (c *fakeClient) Destroy()          { c.destroyed = true }
+
+// newFakeFluxion returns fluxion with a fake client for each policy. Clients made
+// later are fake too, and reject the resources in reject.
+func newFakeFluxion(t *testing.T, reject map[string]bool, policies ...string) (*Fluxion, map[string]*fakeClient) {
+	made := newReapiClient
+	t.Cleanup(func() { newReapiClient = made })
+	newReapiClient = func() reapiClient { return &fakeClient{reject: reject} }
+
+	fluxion := &Fluxion{
+		clients:      map[string]reapiClient{},
+		jobs:         map[uint64]*job{},
+		nextReplayID: replayJobID,
+	}
+	clients := map[string]*fakeClient{}
+	for _, policy := range policies {
+		clients[policy] = &fakeClient{reject: map[string]bool{}}
+		fluxion.clients[policy] = clients[policy]
+	}
+	return fluxion, clients
+}
+
+func TestMirror(t *testing.T) {
+	fluxion, clients := newFakeFluxion(t, map[string]bool{}, "", "first")
+
+	// An allocation and a reservation by one client are added to the other
+	allocation := fluxion.addJob("first", 1, "allocated", false)
+	fluxion.mirror(allocation)
+	reservation := fluxion.addJob("first", 2, "reserved", true)
+	fluxion.mirror(reservation)
+
+	assert.Equal(t, []string{"allocated", "reserved"}, clients[""].allocated)
+	assert.Nil(t, clients["first"].allocated)
+	assert.Equal(t, map[string]uint64{"first": 1, "": replayJobID}, fluxion.jobs[allocation].ids)
+	assert.Equal(t, map[string]uint64{"first": 2, "": replayJobID + 1}, fluxion.jobs[reservation].ids)
+}
+
+func TestMirrorRebuildsDefault(t *testing.T) {
+	reject := map[string]bool{}
+	fluxion, clients := newFakeFluxion(t, reject, "", "first")
+
+	// An allocation that only the default client has
+	kept := fluxion.addJob("", 1, "kept", false)
+	lost := fluxion.addJob("", 2, "lost", false)
+
+	// The default cannot take a match by the other client, and is made again,
+	// but the rebuilt client cannot take one of its own allocations either
+	clients[""].reject["taken"] = true
+	reject["taken"] = true
+	reject["lost"] = true
+	taken := fluxion.addJob("first", 1, "taken", false)
+	fluxion.mirror(taken)
+
+	assert.True(t, clients[""].destroyed)
+	rebuilt := fluxion.clients[""].(*fakeClient)
+	assert.Equal(t, []string{"kept"}, rebuilt.allocated)
+
+	// The one the rebuilt client has is kept, the one no client has is dropped,
+	// and the match by the other client stays there
+	assert.Contains(t, fluxion.jobs, kept)
+	assert.NotContains(t, fluxion.jobs, lost)
+	assert.Equal(t, map[string]uint64{"first": 1}, fluxion.jobs[taken].ids)
+}
+
+func TestSetPolicyKeepsAllocations(t *testing.T) {
+	fluxion, _ := newFakeFluxion(t, map[string]bool{}, "")
+	fluxIDs := []uint64{}
+	for i := 0; i < 8; i++ {
+		fluxIDs = append(fluxIDs, fluxion.addJob("", uint64(i+1), fmt.Sprintf("job-%d", i), i%2 == 1))
+	}
+
+	response, err := fluxion.SetPolicy(context.Background(), &pb.SetPolicyRequest{Policy: "first"})
+	assert.Nil(t, err)
+	assert.Equal(t, "", response.Previous)
+	assert.Equal(t, "first", fluxion.policy)
+
+	// Allocations and reservations are replayed by flux job id
+	cli := fluxion.clients["first"].(*fakeClient)
+	expected := []string{}
+	for i, fluxID := range fluxIDs {
+		expected = append(expected, fmt.Sprintf("job-%d", i))
+		assert.Equal(t, replayJobID+uint64(i), fluxion.jobs[fluxID].ids["first"])
+	}
+	assert.Equal(t, expected, cli.allocated)
+}
@@ -0,0 +1,179 @@
+package fluxion
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	pb "github.com/converged-computing/fluxnetes/pkg/fluxion-grpc"
+	"github.com/converged-computing/fluxnetes/pkg/jgf"
+	klog "k8s.io/klog/v2"
+)
+
+// A snapshot is what fluxion knows: the graph, and each match by flux job id.
+// It is restored into a new sidecar (e.g., on an upgrade) the same way the graph
+// is rebuilt, so allocations are replayed and reservations are asked for again.
+// It is also meant to be read, to see what fluxion had when a group was placed.
+
+// Snapshot is the state of fluxion, written as JSON
+type Snapshot struct {
+	Time   time.Time       `json:"time"`
+	Policy string          `json:"policy"`
+	Graph  json.RawMessage `json:"graph"`
+
+	// The vertex ids given out, so a graph rebuilt after a restore keeps them
+	Registry *jgf.Registry `json:"registry,omitempty"`
+
+	// The next flux job id, so ids are not given out again
+	NextJobID uint64        `json:"nextJobID"`
+	Jobs      []SnapshotJob `json:"jobs"`
+}
+
+// SnapshotJob is a match, with the resources (R) from fluxion
+type SnapshotJob struct {
+	FluxID    uint64          `json:"fluxID"`
+	Policy    string          `json:"policy"`
+	Reserved  bool            `json:"reserved"`
+	Allocated json.RawMessage `json:"allocated"`
+
+	// Start time, which is the ETA for a reservation
+	At int64 `json:"at"`
+}
+
+// Snapshot exports the graph and every allocation and reservation
+func (fluxion *Fluxion) Snapshot(ctx context.Context, in *pb.SnapshotRequest) (*pb.SnapshotResponse, error) {
+
+	fluxion.mutex.Lock()
+	defer fluxion.mutex.Unlock()
+
+	klog.Infof("[Fluxnetes] Received Snapshot request")
+	if fluxion.encoded == "" {
+		return nil, fmt.Errorf("fluxion does not have a graph")
+	}
+	snapshot := Snapshot{
+		Time:      time.Now().UTC(),
+		Policy:    fluxion.policy,
+		Graph:     json.RawMessage(fluxion.encoded),
+		Registry:  fluxion.graphOptions.Registry,
+		NextJobID: fluxion.nextJobID,
+		Jobs:      []SnapshotJob{},
+	}
+	response := &pb.SnapshotResponse{}
+	for fluxID, j := range fluxion.jobs {
+		saved := SnapshotJob{
+			FluxID:    fluxID,
+			Policy:    j.policy,
+			Reserved:  j.reserved,
+			Allocated: json.RawMessage(j.allocated),
+		}
+		if j.allocated == "" {
+			saved.Allocated = json.RawMessage("null")
+		}
+		cli, id, err := fluxion.jobClient(fluxID, j)
+		if err == nil {
+			_, at, _, _, err := cli.Info(int64(id))
+			if err == nil {
+				saved.At = at
+			}
+		}
+		if j.reserved {
+			response.Reservations += 1
+		} else {
+			response.Allocations += 1
+		}
+		snapshot.Jobs = append(snapshot.Jobs, saved)
+	}
+	sort.Slice(snapshot.Jobs, func(i, k int) bool {
+		return snapshot.Jobs[i].FluxID < snapshot.Jobs[k].FluxID
+	})
+
+	encoded, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	response.Snapshot = string(encoded)
+	klog.Infof("[Fluxnetes] Snapshot has %d allocations and %d reservations", response.Allocations, response.Reservations)
+	return response, nil
+}
+
+// Restore replaces the graph and matches with a snapshot. Allocations keep their
+// flux job ids, and one that does not fit the graph is dropped, like on a graph
+// update. Reservations are dropped, and are asked for again on the next match.
+// If the sidecar watches the cluster, the graph is rebuilt on the next node change.
+func (fluxion *Fluxion) Restore(ctx context.Context, in *pb.RestoreRequest) (*pb.RestoreResponse, error) {
+
+	fluxion.mutex.Lock()
+	defer fluxion.mutex.Unlock()
+
+	klog.Infof("[Fluxnetes] Received Restore request")
+	snapshot := Snapshot{}
+	err := json.Unmarshal([]byte(in.Snapshot), &snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read snapshot: %w", err)
+	}
+	graph, err := jgf.ReadFluxJGF(snapshot.Graph)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read snapshot graph: %w", err)
+	}
+	err = graph.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("snapshot graph is not valid: %w", err)
+	}
+
+	// The clients replay what is in jobs, so we put it back if the restore fails
+	previous, previousID := fluxion.jobs, fluxion.nextJobID
+	fluxion.jobs = map[uint64]*job{}
+	fluxion.nextJobID = snapshot.NextJobID
+	dropped := int32(0)
+	for _, saved := range snapshot.Jobs {
+		if saved.Reserved {
+			dropped += 1
+			continue
+		}
+		fluxion.jobs[saved.FluxID] = &job{
+			policy:    saved.Policy,
+			ids:       map[string]uint64{},
+			allocated: string(saved.Allocated),
+		}
+		if saved.FluxID >= fluxion.nextJobID {
+			fluxion.nextJobID = saved.FluxID + 1
+		}
+	}
+	cli, ids, err := fluxion.newClient(string(snapshot.Graph), snapshot.Policy)
+	if err != nil {
+		fluxion.jobs, fluxion.nextJobID = previous, previousID
+		return nil, fmt.Errorf("cannot restore graph for match policy %q: %w", snapshot.Policy, err)
+	}
+	for fluxID, j := range fluxion.jobs {
+		id, ok := ids[fluxID]
+		if !ok {
+			dropped += 1
+			delete(fluxion.jobs, fluxID)
+			continue
+		}
+		j.ids[snapshot.Policy] = id
+	}
+
+	for _, old := range fluxion.clients {
+		old.Destroy()
+	}
+	fluxion.clients = map[string]reapiClient{snapshot.Policy: cli}
+	fluxion.policy = snapshot.Policy
+	fluxion.encoded = string(snapshot.Graph)
+	if snapshot.Registry != nil {
+		fluxion.graphOptions.Registry = snapshot.Registry
+	}
+	fluxion.setGraph(graph)
+
+	// Keep the written graph the same as what fluxion has, for GetResources
+	err = graph.WriteJGF(fluxion.GraphFile)
+	if err != nil {
+		klog.Errorf("[Fluxnetes] Error writing restored JGF: %s", err)
+	}
+
+	rr := &pb.RestoreResponse{Policy: snapshot.Policy, Allocations: int32(len(fluxion.jobs)), Dropped: dropped}
+	klog.Infof("[Fluxnetes] Restored snapshot from %s: %v", snapshot.Time, rr)
+	return rr, nil
+}
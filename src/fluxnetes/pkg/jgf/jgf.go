@@ -21,6 +21,7 @@ import (
 	"log"
 	"os"
 	filepath "path"
+	"strings"
 )
 
 var (
@@ -30,6 +31,15 @@ var (
 	defaultSize      = int64(1)
 	defaultUnit      = ""
 
+	// Memory (in bytes) of one memory vertex
+	DefaultMemoryUnit = int64(1 << 30)
+
+	// CPU (in millicores) of one core vertex
+	DefaultCoreUnit = int64(1000)
+
+	// Ephemeral storage (in bytes) of one storage vertex
+	DefaultStorageUnit = int64(1 << 30)
+
 	// Relations
 	ContainsRelation = "contains"
 	InRelation       = "in"
@@ -44,9 +54,18 @@ var (
 	SocketType      = "socket"
 	SubnetType      = "subnet"
 	MemoryType      = "memory"
+	StorageType     = "storage"
 	NvidiaGPU       = "nvidiagpu"
 	GPUType         = "gpu"
 
+	// Properties
+	// A node that is cordoned or not ready is kept in the graph (so existing
+	// allocations stay valid) but has this property, and jobspecs exclude it
+	DownProperty = "down"
+
+	// A node taint is published as a property with this prefix
+	taintPrefix = "taint:"
+
 	// Paths
 	containmentKey = "containment"
 )
@@ -58,14 +77,41 @@ func NewFluxJGF() FluxJGF {
 	// The index 0 (of the element count) is the cluster
 	counters := map[string]int64{"cluster": int64(1)}
 	return FluxJGF{
-		Graph:   graph{},
-		NodeMap: make(map[string]Node),
+		Graph:     graph{},
+		NodeMap:   make(map[string]Node),
+		positions: make(map[string]int),
 
 		// Counters and lookup for resources
 		Resources: ResourceCounter{counts: counters},
 	}
 }
 
+// NewFluxJGFWithRegistry creates a new graph that takes vertex ids from a registry.
+// The same registry should be used for every rebuild of the graph.
+func NewFluxJGFWithRegistry(registry *Registry) FluxJGF {
+	g := NewFluxJGF()
+	g.registry = registry
+	return g
+}
+
+// ReadFluxJGF reads a graph written by ToBytes (e.g., from a snapshot).
+// Resource counters are not known, so the graph cannot be added to.
+func ReadFluxJGF(data []byte) (*FluxJGF, error) {
+	g := NewFluxJGF()
+	err := json.Unmarshal(data, &g)
+	if err != nil {
+		return nil, err
+	}
+	if len(g.Graph.Nodes) == 0 {
+		return nil, fmt.Errorf("graph has no vertices")
+	}
+	for position, node := range g.Graph.Nodes {
+		g.positions[node.Id] = position
+		g.NodeMap[node.Id] = node
+	}
+	return &g, nil
+}
+
 // ToJson returns a Json string of the graph
 func (g *FluxJGF) ToJson() (string, error) {
 	toprint, err := json.MarshalIndent(g.Graph, "", "\t")
@@ -83,6 +129,239 @@ func getNodePath(root, subpath string) string {
 	return filepath.Clean(path)
 }
 
+// ContainmentPath returns the path a resource (by name and index) would have under a subpath
+func (g *FluxJGF) ContainmentPath(subpath, name string, index int64) string {
+	return getNodePath(g.Resources.RootName, fmt.Sprintf("%s/%s%d", subpath, name, index))
+}
+
+// AllocatedPaths returns the containment paths of the vertices in a resource set (R)
+// returned by fluxion for a match, which is also in json graph format.
+func AllocatedPaths(allocated string) (map[string]bool, error) {
+	paths := map[string]bool{}
+	var R FluxJGF
+	err := json.Unmarshal([]byte(allocated), &R)
+	if err != nil {
+		return paths, err
+	}
+	for _, node := range R.Graph.Nodes {
+		path, ok := node.Metadata.Paths[containmentKey]
+		if ok {
+			paths[path] = true
+		}
+	}
+	return paths, nil
+}
+
+// allocatedGraph is a resource set (R) with the vertices and edges kept as they
+// are, so fields we do not model are not lost when we change it.
+type allocatedGraph struct {
+	Graph struct {
+		Nodes []map[string]interface{} `json:"nodes"`
+		Edges []map[string]interface{} `json:"edges"`
+	} `json:"graph"`
+}
+
+func readAllocated(allocated string) (*allocatedGraph, error) {
+	var R allocatedGraph
+	err := json.Unmarshal([]byte(allocated), &R)
+	return &R, err
+}
+
+func (R *allocatedGraph) toJson() (string, error) {
+	out, err := json.Marshal(R)
+	return string(out), err
+}
+
+// vertexPath returns the containment path of a vertex in R
+func vertexPath(vertex map[string]interface{}) string {
+	metadata, _ := vertex["metadata"].(map[string]interface{})
+	paths, _ := metadata["paths"].(map[string]interface{})
+	path, _ := paths[containmentKey].(string)
+	return path
+}
+
+// edgeKey identifies an edge by the vertices it connects
+func edgeKey(edge map[string]interface{}) string {
+	return fmt.Sprintf("%v:%v", edge["source"], edge["target"])
+}
+
+// addPoolSize adds the size of a pool vertex (memory or storage) in one R to the
+// same vertex in another, since each match took that much of the pool. Other
+// vertices are not changed.
+func addPoolSize(vertex, other map[string]interface{}) {
+	metadata, _ := vertex["metadata"].(map[string]interface{})
+	otherMetadata, _ := other["metadata"].(map[string]interface{})
+	if metadata["type"] != MemoryType && metadata["type"] != StorageType {
+		return
+	}
+	size, _ := metadata["size"].(float64)
+	otherSize, _ := otherMetadata["size"].(float64)
+	metadata["size"] = size + otherSize
+}
+
+// MergeAllocated returns the union of two resource sets (R) from matches in the
+// same graph, such as an allocation and the resources it grew by. Vertices both
+// have (e.g., the cluster and a node with cores in each) are in the result once.
+// A pool vertex (memory or storage) both took part of has the sum of the sizes.
+func MergeAllocated(allocated, extra string) (string, error) {
+	R, err := readAllocated(allocated)
+	if err != nil {
+		return "", err
+	}
+	other, err := readAllocated(extra)
+	if err != nil {
+		return "", err
+	}
+	vertices := map[string]map[string]interface{}{}
+	for _, vertex := range R.Graph.Nodes {
+		vertices[fmt.Sprintf("%v", vertex["id"])] = vertex
+	}
+	for _, vertex := range other.Graph.Nodes {
+		id := fmt.Sprintf("%v", vertex["id"])
+		existing, ok := vertices[id]
+		if ok {
+			addPoolSize(existing, vertex)
+			continue
+		}
+		vertices[id] = vertex
+		R.Graph.Nodes = append(R.Graph.Nodes, vertex)
+	}
+	seen := map[string]bool{}
+	for _, edge := range R.Graph.Edges {
+		seen[edgeKey(edge)] = true
+	}
+	for _, edge := range other.Graph.Edges {
+		key := edgeKey(edge)
+		if !seen[key] {
+			seen[key] = true
+			R.Graph.Edges = append(R.Graph.Edges, edge)
+		}
+	}
+	return R.toJson()
+}
+
+// ReleaseNodes returns a resource set (R) without the nodes named (by basename),
+// the resources under them, and vertices above them that have nothing left under
+// them (e.g., a subnet). The second value is the number of nodes that are left.
+func ReleaseNodes(allocated string, nodes []string) (string, int, error) {
+	R, err := readAllocated(allocated)
+	if err != nil {
+		return "", 0, err
+	}
+	release := map[string]bool{}
+	for _, node := range nodes {
+		release[node] = true
+	}
+
+	// The paths of the nodes to release, which contain the resources to release
+	released := []string{}
+	for _, vertex := range R.Graph.Nodes {
+		metadata, _ := vertex["metadata"].(map[string]interface{})
+		basename, _ := metadata["basename"].(string)
+		if metadata["type"] == NodeType && release[basename] {
+			released = append(released, vertexPath(vertex))
+		}
+	}
+	under := func(path, parent string) bool {
+		return path == parent || strings.HasPrefix(path, parent+"/")
+	}
+	isReleased := func(path string) bool {
+		for _, parent := range released {
+			if under(path, parent) {
+				return true
+			}
+		}
+		return false
+	}
+
+	kept := []map[string]interface{}{}
+	for _, vertex := range R.Graph.Nodes {
+		if !isReleased(vertexPath(vertex)) {
+			kept = append(kept, vertex)
+		}
+	}
+
+	// A vertex above a released node is only kept if something is left under it
+	left := 0
+	vertices := []map[string]interface{}{}
+	for _, vertex := range kept {
+		path := vertexPath(vertex)
+		above := false
+		for _, parent := range released {
+			if strings.HasPrefix(parent, path+"/") {
+				above = true
+				break
+			}
+		}
+		keep := !above
+		if above {
+			for _, other := range kept {
+				otherPath := vertexPath(other)
+				if otherPath != path && under(otherPath, path) {
+					keep = true
+					break
+				}
+			}
+		}
+		if keep {
+			vertices = append(vertices, vertex)
+			metadata, _ := vertex["metadata"].(map[string]interface{})
+			if metadata["type"] == NodeType {
+				left += 1
+			}
+		}
+	}
+
+	ids := map[string]bool{}
+	for _, vertex := range vertices {
+		ids[fmt.Sprintf("%v", vertex["id"])] = true
+	}
+	edges := []map[string]interface{}{}
+	for _, edge := range R.Graph.Edges {
+		if ids[fmt.Sprintf("%v", edge["source"])] && ids[fmt.Sprintf("%v", edge["target"])] {
+			edges = append(edges, edge)
+		}
+	}
+	R.Graph.Nodes = vertices
+	R.Graph.Edges = edges
+	out, err := R.toJson()
+	return out, left, err
+}
+
+// CountType returns the number of vertices of a type in the graph
+func (g *FluxJGF) CountType(resourceType string) int {
+	count := 0
+	for _, node := range g.Graph.Nodes {
+		if node.Metadata.Type == resourceType {
+			count += 1
+		}
+	}
+	return count
+}
+
+// LabelProperty returns the property for a node label with a value. A node also
+// has the label key alone as a property, so a jobspec can ask for it to exist.
+func LabelProperty(key, value string) string {
+	return fmt.Sprintf("%s=%s", key, value)
+}
+
+// TaintProperty returns the property for a node taint (key=value:effect)
+func TaintProperty(taint string) string {
+	return taintPrefix + taint
+}
+
+// HostNames returns the name of each node vertex in the graph, keyed by the
+// node (basename). Fluxion matches a hostlist against these names.
+func (g *FluxJGF) HostNames() map[string]string {
+	names := map[string]string{}
+	for _, node := range g.Graph.Nodes {
+		if node.Metadata.Type == NodeType {
+			names[node.Metadata.Basename] = node.Metadata.Name
+		}
+	}
+	return names
+}
+
 // getContainmentPath returns a new map with containment metadata
 func (g *FluxJGF) getContainmentPath(subpath string) map[string]string {
 	return map[string]string{containmentKey: getNodePath(g.Resources.RootName, subpath)}
@@ -109,12 +388,20 @@ func (g *FluxJGF) MakeEdge(source string, target string, contains string) {
 // MakeSubnet creates a subnet for the graph
 // The name is typically the ip address
 func (g *FluxJGF) MakeSubnet(name string, index int64) Node {
+	return g.MakeDomain(SubnetType, name, "", index)
+}
 
-	// Get a resource counter for the subnet
-	resource := g.Resources.getCounter(name, SubnetType)
+// MakeDomain creates a topology domain (e.g., a region, zone or rack) of a type
+// under a subpath, which is empty for a domain directly under the cluster
+func (g *FluxJGF) MakeDomain(resourceType, name, subpath string, index int64) Node {
+
+	// Get a resource counter for the domain
+	resource := g.Resources.getCounter(name, resourceType)
 	resource.Index = index
-	subpath := resource.NameWithIndex()
-	return g.makeNewNode(resource, subpath, defaultUnit, defaultSize)
+	if subpath != "" {
+		subpath = subpath + "/"
+	}
+	return g.makeNewNode(resource, subpath+resource.NameWithIndex(), defaultUnit, defaultSize)
 }
 
 // makeNewNode is a shared function to make a new node from a resource spec
@@ -126,6 +413,12 @@ func (g *FluxJGF) makeNewNode(
 	subpath, unit string,
 	size int64) Node {
 
+	// With a registry, the id comes from the containment path and not the order we add it
+	paths := g.getContainmentPath(subpath)
+	if g.registry != nil {
+		resource.ElementId = g.registry.vertexId(paths[containmentKey])
+	}
+
 	// A subnet comes directly under the cluster, which is the parent
 	newNode := Node{
 
@@ -151,16 +444,34 @@ func (g *FluxJGF) makeNewNode(
 			Size:      size,
 
 			// subnet is one above root graph, so just need it's name
-			Paths: g.getContainmentPath(subpath),
+			Paths: paths,
 		},
 	}
 
 	// Add the new node to the graph
+	g.positions[newNode.Id] = len(g.Graph.Nodes)
 	g.Graph.Nodes = append(g.Graph.Nodes, newNode)
 	g.NodeMap[newNode.Id] = newNode
 	return newNode
 }
 
+// SetProperty sets a property on a vertex in the graph, which a jobspec
+// can require (or exclude) with a constraint.
+func (g *FluxJGF) SetProperty(id, key, value string) error {
+	position, ok := g.positions[id]
+	if !ok {
+		return fmt.Errorf("vertex %s is not in the graph", id)
+	}
+	node := g.Graph.Nodes[position]
+	if node.Metadata.Properties == nil {
+		node.Metadata.Properties = map[string]string{}
+	}
+	node.Metadata.Properties[key] = value
+	g.Graph.Nodes[position] = node
+	g.NodeMap[id] = node
+	return nil
+}
+
 // MakeNode creates a new node for the graph
 func (g *FluxJGF) MakeNode(name, subpath string, index int64) Node {
 
@@ -187,8 +498,51 @@ func (g *FluxJGF) MakeCore(name, subpath string, index int64) Node {
 	return g.makeNewNode(resource, subpath, defaultUnit, defaultSize)
 }
 
-// MakeMemory creates memory for the graph
-// Flux doesn't understand memory? Not sure if this is doing anything
+// Vertices returns the number of vertices (of a unit, e.g., millicores or bytes)
+// that a node with this much free cpu, memory or storage has. It rounds down,
+// since part of a vertex cannot be used.
+func Vertices(amount, unit int64) int64 {
+	if amount <= 0 {
+		return 0
+	}
+	return amount / unit
+}
+
+// RequestVertices returns the number of vertices (of a unit) a pod that requests
+// this much needs. It rounds up, so a pod is never given less than it requests.
+func RequestVertices(amount, unit int64) int64 {
+	if amount <= 0 {
+		return 0
+	}
+	return (amount + unit - 1) / unit
+}
+
+// Memory and storage vertices are pools, with a size in MB. A jobspec asks for
+// an amount of a pool (in MB), and not for a number of vertices.
+const poolBytes = int64(1 << 20)
+
+// CheckPoolUnit returns an error if a memory or storage unit (in bytes) is not
+// a whole number of MB, since it could not be the size of a vertex
+func CheckPoolUnit(unit int64) error {
+	if unit < poolBytes || unit%poolBytes != 0 {
+		return fmt.Errorf("%d bytes is not a whole number of Mi", unit)
+	}
+	return nil
+}
+
+// PoolSize returns the size (in MB) of a memory or storage vertex of a unit
+func PoolSize(unit int64) int64 {
+	return unit / poolBytes
+}
+
+// RequestSize returns the amount (in MB) of memory or storage a pod that requests
+// this much (in bytes) asks for. Like RequestVertices, it rounds up to whole
+// vertices of the unit, so a pod is never given part of a vertex.
+func RequestSize(amount, unit int64) int64 {
+	return RequestVertices(amount, unit) * PoolSize(unit)
+}
+
+// MakeMemory creates memory for the graph, where the size of a vertex is in MB
 func (g *FluxJGF) MakeMemory(
 	name, subpath string,
 	size, index int64) Node {
@@ -206,6 +560,29 @@ func (g *FluxJGF) MakeMemory(
 	return g.makeNewNode(resource, subpath, unit, size)
 }
 
+// MakeStorage creates ephemeral storage for the graph, where the size of a vertex is in MB
+func (g *FluxJGF) MakeStorage(subpath string, size, index int64) Node {
+	resource := g.Resources.getCounter(StorageType, StorageType)
+	resource.Index = index
+
+	// Here the full containment path will be:
+	// <cluster-root>/<subnet>/<node>/<storage>
+	subpath = fmt.Sprintf("%s/%s", subpath, resource.NameWithIndex())
+	return g.makeNewNode(resource, subpath, "MB", size)
+}
+
+// MakeResource makes one unit of an extended resource (e.g., a device) of a type
+// under the node. The vertex is named for the type.
+func (g *FluxJGF) MakeResource(resourceType, subpath string, index int64) Node {
+	resource := g.Resources.getCounter(resourceType, resourceType)
+	resource.Index = index
+
+	// Here the full containment path will be:
+	// <cluster-root>/<subnet>/<node>/<type><index>
+	subpath = fmt.Sprintf("%s/%s", subpath, resource.NameWithIndex())
+	return g.makeNewNode(resource, subpath, defaultUnit, defaultSize)
+}
+
 // MakeGPU makes a gpu for the graph
 func (g *FluxJGF) MakeGPU(name, subpath string, size, index int64) Node {
 
@@ -1,6 +1,7 @@
 package jgf
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
 
@@ -75,3 +76,184 @@ func TestNewFluxJGF(t *testing.T) {
 	fmt.Println(out)
 
 }
+
+func TestMergeAndReleaseAllocated(t *testing.T) {
+
+	// Two nodes in one subnet, and one in another, each with a core
+	fluxgraph := NewFluxJGF()
+	clusterNode, err := fluxgraph.InitCluster("keebler")
+	assert.Nil(t, err)
+	subnets := []Node{fluxgraph.MakeSubnet("east", 0), fluxgraph.MakeSubnet("west", 1)}
+	for _, subnet := range subnets {
+		fluxgraph.MakeBidirectionalEdge(clusterNode.Id, subnet.Id)
+	}
+	for i, name := range []string{"worker-a", "worker-b", "worker-c"} {
+		subnet := subnets[0]
+		if i == 2 {
+			subnet = subnets[1]
+		}
+		node := fluxgraph.MakeNode(name, subnet.Metadata.Name, int64(i))
+		fluxgraph.MakeBidirectionalEdge(subnet.Id, node.Id)
+		core := fluxgraph.MakeCore(CoreType, fmt.Sprintf("%s/%s", subnet.Metadata.Name, node.Metadata.Name), 0)
+		fluxgraph.MakeBidirectionalEdge(node.Id, core.Id)
+	}
+	encoded, err := fluxgraph.ToBytes()
+	assert.Nil(t, err)
+	all := string(encoded)
+
+	// Releasing the node in the west subnet releases the subnet, but not the cluster
+	east, left, err := ReleaseNodes(all, []string{"worker-c"})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, left)
+	paths, err := AllocatedPaths(east)
+	assert.Nil(t, err)
+	assert.Equal(t, 6, len(paths))
+	assert.True(t, paths["/keebler0"])
+	assert.False(t, paths["/keebler0/west1"])
+
+	// Releasing one of the east nodes keeps the subnet
+	west, left, err := ReleaseNodes(all, []string{"worker-a", "worker-b"})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, left)
+	paths, err = AllocatedPaths(west)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(paths))
+
+	// Merging the two has everything once
+	merged, err := MergeAllocated(east, west)
+	assert.Nil(t, err)
+	var R FluxJGF
+	assert.Nil(t, json.Unmarshal([]byte(merged), &R))
+	assert.Equal(t, len(fluxgraph.Graph.Nodes), len(R.Graph.Nodes))
+	assert.Equal(t, len(fluxgraph.Graph.Edges), len(R.Graph.Edges))
+
+	_, left, err = ReleaseNodes(merged, []string{"worker-a", "worker-b", "worker-c"})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, left)
+}
+
+func TestMergeAllocatedPools(t *testing.T) {
+
+	// One node with a core and a memory pool, where each match took part of the pool
+	match := func(memory int64) string {
+		fluxgraph := NewFluxJGF()
+		clusterNode, err := fluxgraph.InitCluster("keebler")
+		assert.Nil(t, err)
+		node := fluxgraph.MakeNode("worker-a", "", 0)
+		fluxgraph.MakeBidirectionalEdge(clusterNode.Id, node.Id)
+		core := fluxgraph.MakeCore(CoreType, node.Metadata.Name, 0)
+		fluxgraph.MakeBidirectionalEdge(node.Id, core.Id)
+		pool := fluxgraph.MakeMemory(MemoryType, node.Metadata.Name, memory, 0)
+		fluxgraph.MakeBidirectionalEdge(node.Id, pool.Id)
+		encoded, err := fluxgraph.ToBytes()
+		assert.Nil(t, err)
+		return string(encoded)
+	}
+
+	// The group holds both parts of the pool, and the other vertices once
+	merged, err := MergeAllocated(match(256), match(512))
+	assert.Nil(t, err)
+	var R FluxJGF
+	assert.Nil(t, json.Unmarshal([]byte(merged), &R))
+	assert.Equal(t, 4, len(R.Graph.Nodes))
+	for _, vertex := range R.Graph.Nodes {
+		switch vertex.Metadata.Type {
+		case MemoryType:
+			assert.Equal(t, int64(768), vertex.Metadata.Size)
+		default:
+			assert.Equal(t, int64(1), vertex.Metadata.Size)
+		}
+	}
+}
+
+func TestReadGraphAndRegistry(t *testing.T) {
+
+	// A graph whose nodes take ids and indices from a registry
+	registry := NewRegistry()
+	build := func(names ...string) *FluxJGF {
+		fluxgraph := NewFluxJGFWithRegistry(registry)
+		clusterNode, err := fluxgraph.InitCluster("keebler")
+		assert.Nil(t, err)
+		for _, name := range names {
+			node := fluxgraph.MakeNode(name, "", registry.Index(NodeType, name))
+			fluxgraph.MakeBidirectionalEdge(clusterNode.Id, node.Id)
+		}
+		return &fluxgraph
+	}
+	fluxgraph := build("worker-a", "worker-b")
+	encoded, err := fluxgraph.ToBytes()
+	assert.Nil(t, err)
+
+	// The graph read back has the same vertices and host names
+	read, err := ReadFluxJGF(encoded)
+	assert.Nil(t, err)
+	assert.Equal(t, len(fluxgraph.Graph.Nodes), len(read.Graph.Nodes))
+	assert.Equal(t, fluxgraph.HostNames(), read.HostNames())
+	_, err = ReadFluxJGF([]byte(`{"graph": {}}`))
+	assert.NotNil(t, err)
+
+	// A restored registry gives the same ids, and new ones after them
+	saved, err := json.Marshal(registry)
+	assert.Nil(t, err)
+	registry = &Registry{}
+	assert.Nil(t, json.Unmarshal(saved, registry))
+	rebuilt := build("worker-b", "worker-c")
+	ids := func(g *FluxJGF) map[string]string {
+		found := map[string]string{}
+		for _, node := range g.Graph.Nodes {
+			found[node.Metadata.Basename] = node.Id
+		}
+		return found
+	}
+	assert.Equal(t, ids(fluxgraph)["worker-b"], ids(rebuilt)["worker-b"])
+	assert.NotEqual(t, ids(fluxgraph)["worker-a"], ids(rebuilt)["worker-c"])
+	assert.Equal(t, "worker-c2", rebuilt.HostNames()["worker-c"])
+}
+
+func TestValidateAndDiff(t *testing.T) {
+
+	// A node with a core and memory under a subnet
+	build := func(memory int64) *FluxJGF {
+		fluxgraph := NewFluxJGF()
+		clusterNode, err := fluxgraph.InitCluster("keebler")
+		assert.Nil(t, err)
+		subnet := fluxgraph.MakeSubnet("east", 0)
+		fluxgraph.MakeBidirectionalEdge(clusterNode.Id, subnet.Id)
+		node := fluxgraph.MakeNode("worker", subnet.Metadata.Name, 0)
+		fluxgraph.MakeBidirectionalEdge(subnet.Id, node.Id)
+		subpath := fmt.Sprintf("%s/%s", subnet.Metadata.Name, node.Metadata.Name)
+		fluxgraph.MakeBidirectionalEdge(node.Id, fluxgraph.MakeCore(CoreType, subpath, 0).Id)
+		fluxgraph.MakeBidirectionalEdge(node.Id, fluxgraph.MakeMemory(MemoryType, subpath, memory, 0).Id)
+		return &fluxgraph
+	}
+	fluxgraph := build(1024)
+	assert.Nil(t, fluxgraph.Validate())
+
+	// The graph read back is the same
+	encoded, err := fluxgraph.ToBytes()
+	assert.Nil(t, err)
+	read, err := ReadFluxJGF(encoded)
+	assert.Nil(t, err)
+	assert.True(t, Diff(fluxgraph, read).Empty())
+
+	// A missing in edge, a repeated uniq_id and a wrong path are found
+	read.Graph.Edges = read.Graph.Edges[:len(read.Graph.Edges)-1]
+	read.Graph.Nodes[2].Metadata.Uniq_id = 1
+	read.Graph.Nodes[3].Metadata.Paths["containment"] = "/keebler0/worker0/core0"
+	err = read.Validate()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "has no in edge back")
+	assert.Contains(t, err.Error(), "uniq_id 1 is used by vertices 1 and 2")
+	assert.Contains(t, err.Error(), `vertex 3 has containment path "/keebler0/worker0/core0"`)
+
+	// A vertex that changed size, and one that is added with its edges
+	other := build(2048)
+	core := other.MakeCore(CoreType, "east0/worker0", 1)
+	other.MakeBidirectionalEdge("2", core.Id)
+	assert.Nil(t, other.Validate())
+	diff := Diff(fluxgraph, other)
+	assert.Equal(t, []string{"/keebler0/east0/worker0/core1"}, diff.Added)
+	assert.Equal(t, []string{`/keebler0/east0/worker0/memory0: size "1024" -> "2048"`}, diff.Changed)
+	assert.Equal(t, 2, len(diff.AddedEdges))
+	assert.Empty(t, diff.Removed)
+}
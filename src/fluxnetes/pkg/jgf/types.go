@@ -16,7 +16,10 @@ limitations under the License.
 
 package jgf
 
-import "fmt"
+import (
+	"encoding/json"
+	"fmt"
+)
 
 type Node struct {
 	Id       string       `json:"id"`
@@ -63,6 +66,26 @@ type FluxJGF struct {
 
 	// Counters for specific resource types (e.g., rack, node)
 	Resources ResourceCounter `json:"-"`
+
+	// Optional registry to keep vertex ids stable across rebuilds
+	registry *Registry
+
+	// Position of each vertex (by id) in the graph nodes
+	positions map[string]int
+}
+
+// Registry keeps vertex ids and resource indices stable across graph rebuilds.
+// A vertex is known by its containment path, so a node (and its cores, memory
+// and gpus) keeps the same id when other nodes are added or removed, and an
+// allocation made on the previous graph is still valid on the new one.
+// Ids and indices that are no longer used are not given out again.
+type Registry struct {
+	ids    map[string]int64
+	nextId int64
+
+	// Resource indices are by type and then name
+	indices   map[string]map[string]int64
+	nextIndex map[string]int64
 }
 
 // ResourceCounter keeps track of indices for each resource type
@@ -130,6 +153,81 @@ func (r *ResourceCounter) NextResourceIndex(resourceType string) int64 {
 	return nextIndex
 }
 
+// NewRegistry returns an empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		ids:       map[string]int64{},
+		indices:   map[string]map[string]int64{},
+		nextIndex: map[string]int64{},
+	}
+}
+
+// registryJSON is how a registry is saved, so a restored graph keeps its ids
+type registryJSON struct {
+	Ids       map[string]int64            `json:"ids"`
+	NextId    int64                       `json:"nextId"`
+	Indices   map[string]map[string]int64 `json:"indices"`
+	NextIndex map[string]int64            `json:"nextIndex"`
+}
+
+// MarshalJSON writes the ids and indices given out so far
+func (r *Registry) MarshalJSON() ([]byte, error) {
+	return json.Marshal(registryJSON{
+		Ids:       r.ids,
+		NextId:    r.nextId,
+		Indices:   r.indices,
+		NextIndex: r.nextIndex,
+	})
+}
+
+// UnmarshalJSON reads a registry written by MarshalJSON
+func (r *Registry) UnmarshalJSON(data []byte) error {
+	saved := registryJSON{}
+	err := json.Unmarshal(data, &saved)
+	if err != nil {
+		return err
+	}
+	*r = *NewRegistry()
+	r.nextId = saved.NextId
+	for path, id := range saved.Ids {
+		r.ids[path] = id
+	}
+	for resourceType, lookup := range saved.Indices {
+		r.indices[resourceType] = lookup
+	}
+	for resourceType, index := range saved.NextIndex {
+		r.nextIndex[resourceType] = index
+	}
+	return nil
+}
+
+// vertexId returns the id for a vertex path, assigning the next if it is new
+func (r *Registry) vertexId(path string) int64 {
+	id, ok := r.ids[path]
+	if !ok {
+		id = r.nextId
+		r.ids[path] = id
+		r.nextId += 1
+	}
+	return id
+}
+
+// Index returns a stable index for a named resource of a type (e.g., a node)
+func (r *Registry) Index(resourceType, name string) int64 {
+	lookup, ok := r.indices[resourceType]
+	if !ok {
+		lookup = map[string]int64{}
+		r.indices[resourceType] = lookup
+	}
+	index, ok := lookup[name]
+	if !ok {
+		index = r.nextIndex[resourceType]
+		lookup[name] = index
+		r.nextIndex[resourceType] = index + 1
+	}
+	return index
+}
+
 // getCounter returns the counter context for a specific resource type
 func (r *ResourceCounter) getCounter(
 	resourceName string,
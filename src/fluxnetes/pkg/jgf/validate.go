@@ -0,0 +1,242 @@
+package jgf
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// A graph read back (or rebuilt) can be checked before it is given to fluxion,
+// and compared with another, e.g., to check that a graph updated as nodes change
+// is the same as one built from scratch. Vertices are compared by containment
+// path, since that is what stays the same across rebuilds.
+
+// containmentParents returns the parent of each vertex (by id) from the contains edges
+func (g *FluxJGF) containmentParents() map[string][]string {
+	parents := map[string][]string{}
+	for _, e := range g.Graph.Edges {
+		if e.Metadata.Name[containmentKey] == ContainsRelation {
+			parents[e.Target] = append(parents[e.Target], e.Source)
+		}
+	}
+	return parents
+}
+
+// Validate checks that the graph is one fluxion can use: ids and uniq_ids are
+// unique, each containment edge is there in both directions, the containment
+// path of each vertex is the path of its parent and its name, and sizes and
+// units are sane. It returns all of the problems found.
+func (g *FluxJGF) Validate() error {
+	problems := []error{}
+	problem := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Errorf(format, args...))
+	}
+
+	vertices := map[string]Node{}
+	uniqIds := map[int64]string{}
+	for _, node := range g.Graph.Nodes {
+		if _, ok := vertices[node.Id]; ok {
+			problem("vertex id %s is used more than once", node.Id)
+		}
+		vertices[node.Id] = node
+		if other, ok := uniqIds[node.Metadata.Uniq_id]; ok {
+			problem("uniq_id %d is used by vertices %s and %s", node.Metadata.Uniq_id, other, node.Id)
+		}
+		uniqIds[node.Metadata.Uniq_id] = node.Id
+
+		if node.Metadata.Type == "" {
+			problem("vertex %s has no type", node.Id)
+		}
+		if node.Metadata.Name != fmt.Sprintf("%s%d", node.Metadata.Basename, node.Metadata.Id) {
+			problem("vertex %s name %q is not its basename and index", node.Id, node.Metadata.Name)
+		}
+		if node.Metadata.Size < 1 {
+			problem("vertex %s has size %d", node.Id, node.Metadata.Size)
+		}
+		if (node.Metadata.Type == MemoryType || node.Metadata.Type == StorageType) && node.Metadata.Unit == "" {
+			problem("%s vertex %s has no unit", node.Metadata.Type, node.Id)
+		}
+	}
+
+	// Each contains edge has an in edge back, and the other way around
+	relations := map[string]bool{}
+	for _, e := range g.Graph.Edges {
+		relation := e.Metadata.Name[containmentKey]
+		if relation != ContainsRelation && relation != InRelation {
+			problem("edge %s -> %s has relation %q", e.Source, e.Target, relation)
+			continue
+		}
+		if _, ok := vertices[e.Source]; !ok {
+			problem("edge %s -> %s is from a vertex that does not exist", e.Source, e.Target)
+		}
+		if _, ok := vertices[e.Target]; !ok {
+			problem("edge %s -> %s is to a vertex that does not exist", e.Source, e.Target)
+		}
+		relations[e.Source+" "+relation+" "+e.Target] = true
+	}
+	for _, e := range g.Graph.Edges {
+		switch e.Metadata.Name[containmentKey] {
+		case ContainsRelation:
+			if !relations[e.Target+" "+InRelation+" "+e.Source] {
+				problem("edge %s contains %s has no in edge back", e.Source, e.Target)
+			}
+		case InRelation:
+			if !relations[e.Target+" "+ContainsRelation+" "+e.Source] {
+				problem("edge %s in %s has no contains edge back", e.Source, e.Target)
+			}
+		}
+	}
+
+	// There is one root, and every other vertex has one parent it is under
+	parents := g.containmentParents()
+	roots := 0
+	for _, node := range g.Graph.Nodes {
+		path := node.Metadata.Paths[containmentKey]
+		switch len(parents[node.Id]) {
+		case 0:
+			roots += 1
+			if path != "/"+node.Metadata.Name {
+				problem("root vertex %s has containment path %q", node.Id, path)
+			}
+		case 1:
+			parent, ok := vertices[parents[node.Id][0]]
+			if !ok {
+				continue
+			}
+			expected := parent.Metadata.Paths[containmentKey] + "/" + node.Metadata.Name
+			if path != expected {
+				problem("vertex %s has containment path %q, and its parent is at %q", node.Id, path, parent.Metadata.Paths[containmentKey])
+			}
+		default:
+			problem("vertex %s is contained by more than one vertex (%s)", node.Id, strings.Join(parents[node.Id], ", "))
+		}
+	}
+	if roots != 1 {
+		problem("graph has %d root vertices", roots)
+	}
+	return errors.Join(problems...)
+}
+
+// GraphDiff is how one graph differs from another. Vertices are named by
+// containment path, and edges by the paths of their vertices.
+type GraphDiff struct {
+	Added   []string
+	Removed []string
+
+	// A vertex in both graphs with different metadata, with what changed
+	Changed []string
+
+	AddedEdges   []string
+	RemovedEdges []string
+}
+
+// Empty is true if the graphs are the same
+func (d GraphDiff) Empty() bool {
+	return len(d.Added)+len(d.Removed)+len(d.Changed)+len(d.AddedEdges)+len(d.RemovedEdges) == 0
+}
+
+// String returns the diff with a line for each difference
+func (d GraphDiff) String() string {
+	lines := []string{}
+	for _, group := range []struct {
+		prefix string
+		items  []string
+	}{
+		{"+ ", d.Added}, {"- ", d.Removed}, {"~ ", d.Changed},
+		{"+ edge ", d.AddedEdges}, {"- edge ", d.RemovedEdges},
+	} {
+		for _, item := range group.items {
+			lines = append(lines, group.prefix+item)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// vertexFields returns the metadata of a vertex that is compared, by name
+func vertexFields(node Node) map[string]string {
+	properties := []string{}
+	for key, value := range node.Metadata.Properties {
+		properties = append(properties, key+"="+value)
+	}
+	sort.Strings(properties)
+	return map[string]string{
+		"id":         node.Id,
+		"type":       node.Metadata.Type,
+		"basename":   node.Metadata.Basename,
+		"name":       node.Metadata.Name,
+		"index":      fmt.Sprintf("%d", node.Metadata.Id),
+		"uniq_id":    fmt.Sprintf("%d", node.Metadata.Uniq_id),
+		"rank":       fmt.Sprintf("%d", node.Metadata.Rank),
+		"exclusive":  fmt.Sprintf("%t", node.Metadata.Exclusive),
+		"unit":       node.Metadata.Unit,
+		"size":       fmt.Sprintf("%d", node.Metadata.Size),
+		"properties": strings.Join(properties, ","),
+	}
+}
+
+// edgePaths returns the edges of a graph by the paths of their vertices
+func (g *FluxJGF) edgePaths() map[string]bool {
+	paths := map[string]string{}
+	for _, node := range g.Graph.Nodes {
+		paths[node.Id] = node.Metadata.Paths[containmentKey]
+	}
+	edges := map[string]bool{}
+	for _, e := range g.Graph.Edges {
+		edges[fmt.Sprintf("%s %s %s", paths[e.Source], e.Metadata.Name[containmentKey], paths[e.Target])] = true
+	}
+	return edges
+}
+
+// Diff returns how graph b differs from graph a
+func Diff(a, b *FluxJGF) GraphDiff {
+	d := GraphDiff{}
+	before := map[string]Node{}
+	for _, node := range a.Graph.Nodes {
+		before[node.Metadata.Paths[containmentKey]] = node
+	}
+	after := map[string]Node{}
+	for _, node := range b.Graph.Nodes {
+		after[node.Metadata.Paths[containmentKey]] = node
+	}
+
+	for path, node := range after {
+		old, ok := before[path]
+		if !ok {
+			d.Added = append(d.Added, path)
+			continue
+		}
+		oldFields, newFields := vertexFields(old), vertexFields(node)
+		changes := []string{}
+		for field, value := range newFields {
+			if oldFields[field] != value {
+				changes = append(changes, fmt.Sprintf("%s %q -> %q", field, oldFields[field], value))
+			}
+		}
+		if len(changes) > 0 {
+			sort.Strings(changes)
+			d.Changed = append(d.Changed, path+": "+strings.Join(changes, ", "))
+		}
+	}
+	for path := range before {
+		if _, ok := after[path]; !ok {
+			d.Removed = append(d.Removed, path)
+		}
+	}
+
+	oldEdges, newEdges := a.edgePaths(), b.edgePaths()
+	for edge := range newEdges {
+		if !oldEdges[edge] {
+			d.AddedEdges = append(d.AddedEdges, edge)
+		}
+	}
+	for edge := range oldEdges {
+		if !newEdges[edge] {
+			d.RemovedEdges = append(d.RemovedEdges, edge)
+		}
+	}
+	for _, items := range [][]string{d.Added, d.Removed, d.Changed, d.AddedEdges, d.RemovedEdges} {
+		sort.Strings(items)
+	}
+	return d
+}
@@ -18,8 +18,11 @@ package jobspec
 import (
 	"fmt"
 	"log"
+	"sort"
 
+	"github.com/converged-computing/fluxnetes/pkg/defaults"
 	pb "github.com/converged-computing/fluxnetes/pkg/fluxion-grpc"
+	"github.com/converged-computing/fluxnetes/pkg/jgf"
 	"gopkg.in/yaml.v2"
 )
 
@@ -36,15 +39,29 @@ Ps: &pb.PodSpec{
 		},
 */
 
+// ExcludeDown is the constraint for a match now. Nodes that are cordoned or not
+// ready are in the graph, but marked down. It is not used to check if a group can
+// ever be satisfied, since the nodes can come back.
+var ExcludeDown = &Constraint{Properties: []string{"^" + jgf.DownProperty}}
+
 // CreateJobSpecYaml writes the protobuf jobspec into a yaml file
-func CreateJobSpecYaml(spec *pb.PodSpec, count int32) ([]byte, error) {
+// A constraint (if set) is required, and a placement (if set) puts the
+// slots in topology domains. The duration is the walltime fluxion uses
+// to plan reservations.
+func CreateJobSpecYaml(spec *pb.PodSpec, count int32, options Options) ([]byte, error) {
 
 	command := []string{spec.Container}
 	fmt.Println("Labels ", spec.Labels, " ", len(spec.Labels))
 
+	constraints := options.Constraint
+	duration := options.Duration
+	if duration <= 0 {
+		duration = defaults.Duration
+	}
+
 	js := JobSpec{
 		Version:    Version{Version: 9999},
-		Attributes: Attribute{System{Duration: 3600}},
+		Attributes: Attribute{System{Duration: duration, Constraints: constraints}},
 
 		// The name of the task likely needs to correspond with the pod
 		// Since we can't easily change the proto file, for now it is
@@ -59,8 +76,8 @@ func CreateJobSpecYaml(spec *pb.PodSpec, count int32) ([]byte, error) {
 	}
 
 	// Assemble resources!
-	socketResources := createSocketResources(spec)
-	js.Version.Resources = createResources(spec, socketResources, count)
+	socketResources := createSocketResources(spec, options)
+	js.Version.Resources = createResources(spec, socketResources, count, options.Placement)
 
 	// Write bytes to file
 	yamlbytes, err := yaml.Marshal(&js)
@@ -71,54 +88,252 @@ func CreateJobSpecYaml(spec *pb.PodSpec, count int32) ([]byte, error) {
 	return yamlbytes, nil
 }
 
-// createSocketResources creates the socket resources for the JobSpec
-func createSocketResources(spec *pb.PodSpec) []Resource {
+// All returns a constraint that requires each of the (not nil) constraints
+func All(constraints ...*Constraint) *Constraint {
+	required := []*Constraint{}
+	for _, constraint := range constraints {
+		if constraint != nil {
+			required = append(required, constraint)
+		}
+	}
+	if len(required) == 0 {
+		return nil
+	}
+	if len(required) == 1 {
+		return required[0]
+	}
+	return &Constraint{And: required}
+}
+
+// anyOf returns a constraint that requires one of the constraints
+func anyOf(constraints []*Constraint) *Constraint {
+	if len(constraints) == 1 {
+		return constraints[0]
+	}
+	return &Constraint{Or: constraints}
+}
+
+// NodeConstraint translates the node selector and required node affinity of a pod
+// into a constraint on node properties, or nil if there is nothing to require.
+// Only labels that are published in the graph can be matched. A requirement on
+// another label (or with Gt and Lt, which properties cannot express) is left out,
+// and is still checked by the Kubernetes filter plugins.
+func NodeConstraint(spec *pb.PodSpec, published []string) *Constraint {
+	isPublished := map[string]bool{}
+	for _, key := range published {
+		isPublished[key] = true
+	}
+	required := []*Constraint{}
+
+	keys := []string{}
+	for key := range spec.NodeSelector {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	properties := []string{}
+	for _, key := range keys {
+		if isPublished[key] {
+			properties = append(properties, jgf.LabelProperty(key, spec.NodeSelector[key]))
+		}
+	}
+	if len(properties) > 0 {
+		required = append(required, &Constraint{Properties: properties})
+	}
+
+	// A node can match any term, so if one term has nothing we can require, neither does the affinity
+	terms := []*Constraint{}
+	for _, term := range spec.NodeAffinity {
+		requirements := []*Constraint{}
+		for _, requirement := range term.Requirements {
+			if !isPublished[requirement.Key] {
+				continue
+			}
+			constraint := requirementConstraint(requirement)
+			if constraint != nil {
+				requirements = append(requirements, constraint)
+			}
+		}
+		if len(requirements) == 0 {
+			terms = []*Constraint{}
+			break
+		}
+		terms = append(terms, All(requirements...))
+	}
+	if len(terms) > 0 {
+		required = append(required, anyOf(terms))
+	}
+	return All(required...)
+}
+
+// requirementConstraint translates one node affinity requirement, or returns nil
+func requirementConstraint(requirement *pb.NodeSelectorRequirement) *Constraint {
+	key := requirement.Key
+	switch requirement.Operator {
+	case "In":
+		values := []*Constraint{}
+		for _, value := range requirement.Values {
+			values = append(values, &Constraint{Properties: []string{jgf.LabelProperty(key, value)}})
+		}
+		if len(values) == 0 {
+			return nil
+		}
+		return anyOf(values)
+	case "NotIn":
+		properties := []string{}
+		for _, value := range requirement.Values {
+			properties = append(properties, "^"+jgf.LabelProperty(key, value))
+		}
+		if len(properties) == 0 {
+			return nil
+		}
+		return &Constraint{Properties: properties}
+	case "Exists":
+		return &Constraint{Properties: []string{key}}
+	case "DoesNotExist":
+		return &Constraint{Properties: []string{"^" + key}}
+	}
+	return nil
+}
+
+// PackPlacement puts all slots in one domain of a topology level
+func PackPlacement(level string) *Placement {
+	return &Placement{Type: level, Count: 1}
+}
+
+// SpreadPlacement spreads count slots evenly across as many domains of a topology
+// level as there are, or fewer so each gets the same number. It returns nil for a
+// count that cannot be split across two or more of the domains (e.g., 7 over 3
+// zones), since putting it in one domain would be the opposite of a spread.
+func SpreadPlacement(level string, count int32, domains int) *Placement {
+	for n := int64(domains); n > 1; n-- {
+		if n <= int64(count) && int64(count)%n == 0 {
+			return &Placement{Type: level, Count: n}
+		}
+	}
+	return nil
+}
+
+// CoreCount returns the number of core vertices (of unit millicores) a pod needs.
+// The request is rounded up, and a pod that does not request cpu needs one.
+func CoreCount(spec *pb.PodSpec, unit int64) int64 {
+	if unit <= 0 {
+		unit = jgf.DefaultCoreUnit
+	}
+	millicores := spec.MilliCpu
+	if millicores <= 0 {
+		millicores = int64(spec.Cpu) * 1000
+	}
+	count := jgf.RequestVertices(millicores, unit)
+	if count < 1 {
+		count = 1
+	}
+	return count
+}
+
+// createSocketResources creates the socket resources for the JobSpec,
+// including the extended resources (e.g., gpus) of the pod. Cpu, memory and
+// storage are rounded up to whole vertices, which are the same units as the graph.
+// Memory and storage vertices are pools, so they are asked for in MB.
+func createSocketResources(spec *pb.PodSpec, options Options) []Resource {
 
 	socketResources := []Resource{
 		{
-			Type: "core", Count: int64(spec.Cpu),
+			Type: jgf.CoreType, Count: CoreCount(spec, options.CoreUnit),
 		},
 	}
 
-	// TODO double check what we are converting from -> to
+	memoryUnit := options.MemoryUnit
+	if memoryUnit <= 0 {
+		memoryUnit = jgf.DefaultMemoryUnit
+	}
 	if spec.Memory > 0 {
-		toMB := spec.Memory >> 20
-		socketResources = append(socketResources, Resource{Type: "memory", Count: toMB})
+		size := jgf.RequestSize(spec.Memory, memoryUnit)
+		socketResources = append(socketResources, Resource{Type: jgf.MemoryType, Count: size})
+	}
+
+	storageUnit := options.StorageUnit
+	if storageUnit <= 0 {
+		storageUnit = jgf.DefaultStorageUnit
+	}
+	if spec.Storage > 0 {
+		size := jgf.RequestSize(spec.Storage, storageUnit)
+		socketResources = append(socketResources, Resource{Type: jgf.StorageType, Count: size})
 	}
 
-	if spec.Gpu > 0 {
-		socketResources = append(socketResources, Resource{Type: "gpu", Count: spec.Gpu})
+	for _, resource := range options.Resources {
+		if resource.Count > 0 {
+			socketResources = append(socketResources, resource)
+		}
 	}
 	return socketResources
 }
 
-// createResources assembles the list of JobSpec resources
-func createResources(spec *pb.PodSpec, socketResources []Resource, count int32) []Resource {
+// createResources assembles the list of JobSpec resources. An exclusive
+// group gets whole nodes, with one slot (pod) under each node.
+func createResources(spec *pb.PodSpec, socketResources []Resource, count int32, placement *Placement) []Resource {
+	hasPlacement := placement != nil && placement.Count > 0
+
+	// With a placement, each domain has an even share of the slots
+	perDomain := int64(count)
+	if hasPlacement {
+		perDomain = int64(count) / placement.Count
+	}
 
 	slotResource := []Resource{
 		{
 			Type:  "slot",
-			Count: int64(count),
+			Count: perDomain,
 			Label: "default",
 			With:  socketResources,
 		},
 	}
+	if spec.Exclusive {
+		slotResource = []Resource{
+			{
+				Type:      jgf.NodeType,
+				Count:     perDomain,
+				Exclusive: true,
+				With: []Resource{
+					{
+						Type:  "slot",
+						Count: 1,
+						Label: "default",
+						With:  socketResources,
+					},
+				},
+			},
+		}
+	}
+
+	if hasPlacement {
+		return []Resource{
+			{
+				Type:  placement.Type,
+				Count: placement.Count,
+				With:  slotResource,
+			},
+		}
+	}
 
 	// Presence of the zone label means we need to add a subnet
 	if len(spec.Labels) > 0 {
 		for _, label := range spec.Labels {
 			if label == "zone" {
+				with := []Resource{
+					{
+						Type:  "node",
+						Count: 1,
+						With:  slotResource,
+					},
+				}
+				if spec.Exclusive {
+					with = slotResource
+				}
 				nodeResource := []Resource{
 					{
 						Type:  "subnet",
 						Count: 1,
-						With: []Resource{
-							{
-								Type:  "node",
-								Count: 1,
-								With:  slotResource,
-							},
-						},
+						With:  with,
 					},
 				}
 				return nodeResource
@@ -0,0 +1,112 @@
+package jobspec
+
+import (
+	"testing"
+
+	pb "github.com/converged-computing/fluxnetes/pkg/fluxion-grpc"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+func TestNodeConstraint(t *testing.T) {
+	published := []string{"kubernetes.io/arch", "pool"}
+
+	// Nothing to require
+	assert.Nil(t, NodeConstraint(&pb.PodSpec{}, published))
+
+	// A selector on a label that is not published is left out
+	spec := &pb.PodSpec{NodeSelector: map[string]string{"kubernetes.io/arch": "arm64", "other": "value"}}
+	assert.Equal(t, &Constraint{Properties: []string{"kubernetes.io/arch=arm64"}}, NodeConstraint(spec, published))
+
+	// Affinity terms are any of, and requirements in a term are all of
+	spec = &pb.PodSpec{
+		NodeAffinity: []*pb.NodeSelectorTerm{
+			{Requirements: []*pb.NodeSelectorRequirement{
+				{Key: "pool", Operator: "In", Values: []string{"a", "b"}},
+				{Key: "kubernetes.io/arch", Operator: "NotIn", Values: []string{"arm64"}},
+			}},
+			{Requirements: []*pb.NodeSelectorRequirement{
+				{Key: "pool", Operator: "DoesNotExist"},
+			}},
+		},
+	}
+	expected := &Constraint{Or: []*Constraint{
+		{And: []*Constraint{
+			{Or: []*Constraint{
+				{Properties: []string{"pool=a"}},
+				{Properties: []string{"pool=b"}},
+			}},
+			{Properties: []string{"^kubernetes.io/arch=arm64"}},
+		}},
+		{Properties: []string{"^pool"}},
+	}}
+	assert.Equal(t, expected, NodeConstraint(spec, published))
+
+	// A term with nothing we can require matches any node
+	spec.NodeAffinity = append(spec.NodeAffinity, &pb.NodeSelectorTerm{
+		Requirements: []*pb.NodeSelectorRequirement{{Key: "pool", Operator: "Gt", Values: []string{"1"}}},
+	})
+	assert.Nil(t, NodeConstraint(spec, published))
+}
+
+func TestSpreadPlacement(t *testing.T) {
+	assert.Equal(t, &Placement{Type: "zone", Count: 3}, SpreadPlacement("zone", 6, 3))
+	assert.Equal(t, &Placement{Type: "zone", Count: 2}, SpreadPlacement("zone", 4, 3))
+	assert.Equal(t, &Placement{Type: "zone", Count: 2}, SpreadPlacement("zone", 2, 4))
+	assert.Equal(t, &Placement{Type: "zone", Count: 4}, SpreadPlacement("zone", 4, 5))
+
+	// A count that cannot be split is not placed, and not put in one domain
+	assert.Nil(t, SpreadPlacement("zone", 7, 3))
+	assert.Nil(t, SpreadPlacement("zone", 5, 3))
+	assert.Nil(t, SpreadPlacement("zone", 1, 3))
+	assert.Nil(t, SpreadPlacement("zone", 4, 0))
+}
+
+func TestCoreCount(t *testing.T) {
+	// Whole cores, as before
+	assert.Equal(t, int64(1), CoreCount(&pb.PodSpec{Cpu: 1, MilliCpu: 250}, 0))
+	assert.Equal(t, int64(2), CoreCount(&pb.PodSpec{Cpu: 2}, 1000))
+
+	// Requests are rounded up to the unit
+	assert.Equal(t, int64(3), CoreCount(&pb.PodSpec{Cpu: 1, MilliCpu: 250}, 100))
+	assert.Equal(t, int64(1), CoreCount(&pb.PodSpec{}, 100))
+}
+
+func TestExclusive(t *testing.T) {
+	socket := []Resource{{Type: "core", Count: 1}}
+	slot := Resource{Type: "slot", Count: 1, Label: "default", With: socket}
+
+	// Each pod is a slot under its own exclusive node
+	resources := createResources(&pb.PodSpec{Exclusive: true}, socket, 4, nil)
+	assert.Equal(t, []Resource{{Type: "node", Count: 4, Exclusive: true, With: []Resource{slot}}}, resources)
+
+	// And the nodes are split across domains of a placement
+	resources = createResources(&pb.PodSpec{Exclusive: true}, socket, 4, SpreadPlacement("zone", 4, 2))
+	assert.Equal(t, []Resource{{Type: "zone", Count: 2, With: []Resource{{Type: "node", Count: 2, Exclusive: true, With: []Resource{slot}}}}}, resources)
+}
+
+func TestDuration(t *testing.T) {
+	duration := func(options Options) int64 {
+		bytes, err := CreateJobSpecYaml(&pb.PodSpec{Cpu: 1}, 1, options)
+		assert.Nil(t, err)
+		js := JobSpec{}
+		assert.Nil(t, yaml.Unmarshal(bytes, &js))
+		return js.Attributes.SystemAttr.Duration
+	}
+	assert.Equal(t, int64(3600), duration(Options{}))
+	assert.Equal(t, int64(120), duration(Options{Duration: 120}))
+}
+
+func TestConstraints(t *testing.T) {
+	constraints := func(options Options) *Constraint {
+		bytes, err := CreateJobSpecYaml(&pb.PodSpec{Cpu: 1}, 1, options)
+		assert.Nil(t, err)
+		js := JobSpec{}
+		assert.Nil(t, yaml.Unmarshal(bytes, &js))
+		return js.Attributes.SystemAttr.Constraints
+	}
+
+	// Nodes that are down are only excluded when asked, e.g., not for satisfy
+	assert.Nil(t, constraints(Options{}))
+	assert.Equal(t, ExcludeDown, constraints(Options{Constraint: ExcludeDown}))
+}
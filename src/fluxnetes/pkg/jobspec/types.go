@@ -22,14 +22,58 @@ type Version struct {
 }
 
 type Resource struct {
-	Type  string     `yaml:"type"`
-	Count int64      `yaml:"count"`
-	Label string     `yaml:"label,omitempty"`
-	With  []Resource `yaml:"with,omitempty"`
+	Type      string     `yaml:"type"`
+	Count     int64      `yaml:"count"`
+	Exclusive bool       `yaml:"exclusive,omitempty"`
+	Label     string     `yaml:"label,omitempty"`
+	With      []Resource `yaml:"with,omitempty"`
 }
 
 type System struct {
-	Duration int64 `yaml:"duration,omitempty"`
+	Duration    int64       `yaml:"duration,omitempty"`
+	Constraints *Constraint `yaml:"constraints,omitempty"`
+}
+
+// Constraint on the resources (RFC 31). A property with a leading ^ must not be present
+// A hostlist limits the nodes (by name in the graph) and constraints can be combined.
+type Constraint struct {
+	Properties []string      `yaml:"properties,omitempty"`
+	Hostlist   []string      `yaml:"hostlist,omitempty"`
+	And        []*Constraint `yaml:"and,omitempty"`
+	Or         []*Constraint `yaml:"or,omitempty"`
+	Not        []*Constraint `yaml:"not,omitempty"`
+}
+
+// Placement asks for the slots to be split evenly across a number of domains
+// of a topology level (e.g., one zone, or four racks)
+type Placement struct {
+	Type  string
+	Count int64
+}
+
+// Options for creating a jobspec beyond the pod resources and count
+type Options struct {
+
+	// Required in addition to nodes being up
+	Constraint *Constraint
+
+	// Where the slots are placed in the topology
+	Placement *Placement
+
+	// Extended resources (e.g., gpus) each slot needs
+	Resources []Resource
+
+	// Memory (in bytes) of one memory vertex in the graph. The default is 1Gi.
+	MemoryUnit int64
+
+	// CPU (in millicores) of one core vertex in the graph. The default is a whole core.
+	CoreUnit int64
+
+	// Ephemeral storage (in bytes) of one storage vertex in the graph. The default is 1Gi.
+	StorageUnit int64
+
+	// Walltime (in seconds) of the job. The default is an hour.
+	Duration int64
 }
 
 type Attribute struct {
@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/converged-computing/fluxnetes/pkg/defaults"
+	"github.com/converged-computing/fluxnetes/pkg/jgf"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// GraphFlags are the command line options for how the resource graph is built,
+// shared by the fluxion service and commands that build the same graph
+type GraphFlags struct {
+	label             *string
+	nodeLabels        *string
+	topology          *string
+	extendedResources *string
+	memoryUnit        *string
+	coreUnit          *string
+	storageUnit       *string
+}
+
+// NewGraphFlags adds the graph options to a flag set
+func NewGraphFlags(flags *flag.FlagSet) *GraphFlags {
+	return &GraphFlags{
+		label:             flags.String("label", "", "Label name for fluxnetes dedicated nodes"),
+		nodeLabels:        flags.String("node-labels", strings.Join(defaults.NodeLabels, ","), "Comma separated node labels to publish as properties for node selectors and affinity"),
+		topology:          flags.String("topology", defaults.Topology, "Comma separated topology levels (type=label) between the cluster and nodes, from the top down"),
+		extendedResources: flags.String("extended-resources", defaults.ExtendedResources, "Comma separated extended resources (name=type:unit) to add under nodes in the graph"),
+		memoryUnit:        flags.String("memory-unit", defaults.MemoryUnit, "Memory of one memory vertex in the graph (e.g., 1Gi or 256Mi)"),
+		coreUnit:          flags.String("core-unit", defaults.CoreUnit, "CPU of one core vertex in the graph, at most a core (e.g., 1 or 100m)"),
+		storageUnit:       flags.String("storage-unit", defaults.StorageUnit, "Ephemeral storage of one storage vertex in the graph (e.g., 1Gi or 10Gi)"),
+	}
+}
+
+// Options returns the graph options from the parsed flags
+func (f *GraphFlags) Options() (GraphOptions, error) {
+	levels, err := ParseTopology(*f.topology)
+	if err != nil {
+		return GraphOptions{}, fmt.Errorf("invalid topology: %w", err)
+	}
+	resources, err := ParseExtendedResources(*f.extendedResources)
+	if err != nil {
+		return GraphOptions{}, fmt.Errorf("invalid extended resources: %w", err)
+	}
+	memory, err := resource.ParseQuantity(*f.memoryUnit)
+	if err == nil {
+		err = jgf.CheckPoolUnit(memory.Value())
+	}
+	if err != nil {
+		return GraphOptions{}, fmt.Errorf("invalid memory unit %q, it must be a whole number of Mi", *f.memoryUnit)
+	}
+	cores, err := resource.ParseQuantity(*f.coreUnit)
+	if err != nil || cores.MilliValue() < 1 || cores.MilliValue() > 1000 {
+		return GraphOptions{}, fmt.Errorf("invalid core unit %q, it must be between 1m and 1", *f.coreUnit)
+	}
+	storage, err := resource.ParseQuantity(*f.storageUnit)
+	if err == nil {
+		err = jgf.CheckPoolUnit(storage.Value())
+	}
+	if err != nil {
+		return GraphOptions{}, fmt.Errorf("invalid storage unit %q, it must be a whole number of Mi", *f.storageUnit)
+	}
+	return GraphOptions{
+		SkipLabel:   *f.label,
+		Labels:      strings.Split(*f.nodeLabels, ","),
+		Topology:    levels,
+		Resources:   resources,
+		MemoryUnit:  memory.Value(),
+		CoreUnit:    cores.MilliValue(),
+		StorageUnit: storage.Value(),
+	}, nil
+}
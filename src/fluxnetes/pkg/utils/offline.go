@@ -0,0 +1,22 @@
+package utils
+
+import (
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// The graph can also be built outside of the cluster from a kubeconfig, to
+// reproduce a production graph elsewhere.
+
+// GetClientset returns a clientset for a kubeconfig, or for the cluster we
+// are running in if the kubeconfig is empty
+func GetClientset(kubeconfig string) (kubernetes.Interface, error) {
+	if kubeconfig == "" {
+		return GetInClusterClientset()
+	}
+	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	return kubernetes.NewForConfig(config)
+}
@@ -1,5 +1,9 @@
 package utils
 
+import (
+	"github.com/converged-computing/fluxnetes/pkg/jgf"
+)
+
 // PodSpec is a temporary holder for the protobuf
 // variant that it will be converted to. We could
 // remove it, but since we need to refactor to use
@@ -13,3 +17,55 @@ type PodSpec struct {
 	Storage   int64
 	Labels    []string
 }
+
+// GraphOptions customize how the resource graph is built from the cluster
+type GraphOptions struct {
+
+	// Nodes with this label (if set) are not added to the graph
+	SkipLabel string
+
+	// The registry keeps vertex ids stable across builds (a new one is used if unset)
+	Registry *jgf.Registry
+
+	// Pods from this scheduler (if set) are not subtracted from what a node has
+	// available, because their resources are accounted for by fluxion allocations
+	OwnScheduler string
+
+	// Containment paths of vertices that are allocated, and must be kept
+	Allocated map[string]bool
+
+	// Node labels to publish as properties on the node vertices
+	Labels []string
+
+	// The levels of topology domains between the cluster and nodes, from the
+	// top down. The default is a subnet for each zone.
+	Topology []TopologyLevel
+
+	// Extended resources to add under nodes. The default is nvidia.com/gpu.
+	Resources []ExtendedResource
+
+	// Memory (in bytes) of one memory vertex. The default is 1Gi.
+	MemoryUnit int64
+
+	// CPU (in millicores) of one core vertex. The default is a whole core.
+	CoreUnit int64
+
+	// Ephemeral storage (in bytes) of one storage vertex. The default is 1Gi.
+	StorageUnit int64
+}
+
+// ExtendedResource is an extended resource (e.g., from a device plugin) in the graph.
+// Each unit of it on a node is a vertex of the type, and a pod request is rounded
+// up to units. A device has a unit of 1, and hugepages have the page size.
+type ExtendedResource struct {
+	Name string
+	Type string
+	Unit int64
+}
+
+// TopologyLevel is a level of topology domains in the graph. Nodes with the
+// same value for the label are in the same domain, which has the vertex type.
+type TopologyLevel struct {
+	Type  string
+	Label string
+}
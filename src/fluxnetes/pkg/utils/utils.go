@@ -3,8 +3,8 @@ package utils
 import (
 	"context"
 	"fmt"
-
-	klog "k8s.io/klog/v2"
+	"sort"
+	"strings"
 
 	"encoding/json"
 
@@ -21,83 +21,104 @@ import (
 var (
 	controlPlaneLabel  = "node-role.kubernetes.io/control-plane"
 	defaultClusterName = "k8scluster"
-)
 
-// RegisterExisting uses the in cluster API to get existing pods
-// This is actually the same as computeTotalRequests but I wanted to compare the two
-// It is currently not being used. The main difference is that below, we are essentially
-// rounding the cpu to the smaller unit (logically for the graph) but losing some
-// granularity, if we think "milli" values have feet.
-func RegisterExisting(clientset *kubernetes.Clientset, ctx context.Context) (map[string]PodSpec, error) {
+	// A subnet for each zone, which is the graph we always had
+	DefaultTopology = []TopologyLevel{{Type: jgf.SubnetType, Label: "topology.kubernetes.io/zone"}}
 
-	// We are using PodSpec as a holder for a *summary* of cpu/memory being used
-	// by the node, it is a summation across pods we find on each one
-	nodes := map[string]PodSpec{}
+	// Vertex types that cannot be used for a topology level
+	reservedTypes = []string{jgf.ClusterType, jgf.NodeType, jgf.CoreType, jgf.MemoryType, jgf.StorageType, jgf.GPUType, "slot"}
 
-	// get pods in all the namespaces by omitting namespace
-	// Or specify namespace to get pods in particular namespace
-	pods, err := clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+	// Nvidia gpus, which is what we always had
+	DefaultResources = []ExtendedResource{{Name: "nvidia.com/gpu", Type: jgf.GPUType, Unit: 1}}
+)
+
+// CreateInClusterJGF creates the Json Graph Format from the Kubernetes API
+func CreateInClusterJGF(filename, skipLabel string) error {
+	clientset, err := GetInClusterClientset()
 	if err != nil {
-		klog.Infof("Error listing pods: %s\n", err)
-		return nodes, err
+		return err
 	}
-	klog.Infof("Found %d existing pods in the cluster\n", len(pods.Items))
-
-	// Create a new PodSpec for each
-	for _, pod := range pods.Items {
-
-		// Add the node to our lookup if we don't have it yet
-		_, ok := nodes[pod.Spec.NodeName]
-		if !ok {
-			nodes[pod.Spec.NodeName] = PodSpec{}
-		}
-		ps := nodes[pod.Spec.NodeName]
-
-		for _, container := range pod.Spec.Containers {
-			specRequests := container.Resources.Requests
-			ps.Cpu += int32(specRequests.Cpu().Value())
-			ps.Memory += specRequests.Memory().Value()
-			ps.Storage += specRequests.StorageEphemeral().Value()
-
-			specLimits := container.Resources.Limits
-			gpuSpec := specLimits["nvidia.com/gpu"]
-			ps.Gpu += gpuSpec.Value()
-		}
-		nodes[pod.Spec.NodeName] = ps
+	fluxgraph, err := BuildJGF(context.Background(), clientset, GraphOptions{SkipLabel: skipLabel})
+	if err != nil {
+		return err
 	}
-	return nodes, nil
+
+	// Get the jgf back as bytes, and we will return string
+	return fluxgraph.WriteJGF(filename)
 }
 
-// CreateInClusterJGF creates the Json Graph Format from the Kubernetes API
-func CreateInClusterJGF(filename, skipLabel string) error {
-	ctx := context.Background()
+// GetInClusterClientset returns a clientset for the cluster we are running in
+func GetInClusterClientset() (kubernetes.Interface, error) {
 	config, err := rest.InClusterConfig()
 	if err != nil {
 		fmt.Println("Error getting InClusterConfig")
-		return err
+		return nil, err
 	}
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
 		fmt.Printf("Error getting ClientSet: %s", err)
-		return err
+		return nil, err
 	}
+	return clientset, nil
+}
+
+// IsControlPlane returns true if the node is for the control plane
+func IsControlPlane(node *corev1.Node) bool {
+	_, ok := node.Labels[controlPlaneLabel]
+	return ok
+}
+
+// IsNodeDown returns true if the node is cordoned (unschedulable) or not ready
+func IsNodeDown(node *corev1.Node) bool {
+	if node.Spec.Unschedulable {
+		return true
+	}
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status != corev1.ConditionTrue
+		}
+	}
+	// A node that has not reported ready is not ready
+	return true
+}
+
+// BuildJGF creates the Json Graph Format for the nodes in the cluster.
+// The registry keeps vertex ids stable, so the graph can be rebuilt when nodes
+// change and allocations on the previous graph are still valid. Nodes that are
+// cordoned or not ready are kept in the graph but marked down. Pods from other
+// schedulers (e.g., the default scheduler, daemonsets, static pods) take cores
+// and memory away from the node, and vertices that are allocated are the last
+// to go, so allocations can be replayed on the new graph.
+func BuildJGF(
+	ctx context.Context,
+	clientset kubernetes.Interface,
+	options GraphOptions,
+) (*jgf.FluxJGF, error) {
+
+	registry := options.Registry
+	if registry == nil {
+		registry = jgf.NewRegistry()
+	}
+
 	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
 	if err != nil {
 		fmt.Printf("Error listing nodes: %s", err)
-		return err
+		return nil, err
 	}
 
 	// Create a Flux Json Graph Format (JGF) with all cluster nodes
-	fluxgraph := jgf.NewFluxJGF()
+	fluxgraph := jgf.NewFluxJGFWithRegistry(registry)
 
 	// Initialize the cluster. The top level of the graph is the cluster
 	// This assumes fluxion is only serving one cluster.
 	// previous comments indicate that we choose between the level
 	// of a rack and a subnet. A rack doesn't make sense (the nodes could
-	// be on multiple racks) so subnet is likely the right abstraction
+	// be on multiple racks) so subnet is likely the right abstraction.
+	// The levels below the cluster are now configurable (options.Topology),
+	// and default to a subnet for each zone.
 	clusterNode, err := fluxgraph.InitCluster(defaultClusterName)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	fmt.Println("Number nodes ", len(nodes.Items))
 
@@ -107,63 +128,120 @@ func CreateInClusterJGF(filename, skipLabel string) error {
 	var totalAllocCpu int64
 	totalAllocCpu = 0
 
-	// Keep a lookup of subnet nodes in case we see one twice
+	topology := options.Topology
+	if len(topology) == 0 {
+		topology = DefaultTopology
+	}
+	resources := options.Resources
+	if len(resources) == 0 {
+		resources = DefaultResources
+	}
+	memoryUnit := options.MemoryUnit
+	if memoryUnit <= 0 {
+		memoryUnit = jgf.DefaultMemoryUnit
+	}
+	coreUnit := options.CoreUnit
+	if coreUnit <= 0 {
+		coreUnit = jgf.DefaultCoreUnit
+	}
+	storageUnit := options.StorageUnit
+	if storageUnit <= 0 {
+		storageUnit = jgf.DefaultStorageUnit
+	}
+	err = jgf.CheckPoolUnit(memoryUnit)
+	if err != nil {
+		return nil, fmt.Errorf("invalid memory unit: %w", err)
+	}
+	err = jgf.CheckPoolUnit(storageUnit)
+	if err != nil {
+		return nil, fmt.Errorf("invalid storage unit: %w", err)
+	}
+
+	// Keep a lookup of topology domains (by path) in case we see one twice
 	// We don't want to create a new entity for it in the graph
-	subnetLookup := map[string]jgf.Node{}
-	var subnetCounter int64 = 0
+	domainLookup := map[string]jgf.Node{}
 
-	for nodeCount, node := range nodes.Items {
+	for _, node := range nodes.Items {
 
 		// We should not be scheduling to the control plane
-		_, ok := node.Labels[controlPlaneLabel]
-		if ok {
+		if IsControlPlane(&node) {
 			fmt.Println("Skipping control plane node ", node.GetName())
 			continue
 		}
 
 		// Anything labeled with "skipLabel" meaning it is present,
 		// should be skipped
-		if skipLabel != "" {
-			_, ok := node.Labels[skipLabel]
+		if options.SkipLabel != "" {
+			_, ok := node.Labels[options.SkipLabel]
 			if ok {
 				fmt.Printf("Skipping node %s\n", node.GetName())
 				continue
 			}
 		}
 
-		if node.Spec.Unschedulable {
-			fmt.Printf("Skipping node %s, unschedulable\n", node.GetName())
-			continue
-		}
-
 		fieldselector, err := fields.ParseSelector("spec.nodeName=" + node.GetName() + ",status.phase!=" + string(corev1.PodSucceeded) + ",status.phase!=" + string(corev1.PodFailed))
 		if err != nil {
-			return err
+			return nil, err
 		}
 		pods, err := clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{
 			FieldSelector: fieldselector.String(),
 		})
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		// Have we seen this subnet node before?
-		subnetName := node.Labels["topology.kubernetes.io/zone"]
-		subnetNode, exists := subnetLookup[subnetName]
-		if !exists {
-			// Build the subnet according to topology.kubernetes.io/zone label
-			subnetNode = fluxgraph.MakeSubnet(subnetName, subnetCounter)
-			subnetCounter += 1
+		// Pods we scheduled are allocated in fluxion, if we know about them
+		own := &corev1.PodList{}
+		if options.OwnScheduler != "" {
+			own = selectScheduler(pods, options.OwnScheduler)
+			pods = filterScheduler(pods, options.OwnScheduler)
+		}
 
-			// This is one example of bidirectional, I won't document in
-			// all following occurrences but this is what the function does
-			// [cluster] -> contains -> [subnet]
-			// [subnet]  ->       in -> [cluster]
-			fluxgraph.MakeBidirectionalEdge(clusterNode.Id, subnetNode.Id)
+		// Walk down the topology domains (e.g., region, zone, rack) to the node,
+		// making any domain we have not seen yet. The subpath (from and not
+		// including root) is the path of domains.
+		parent := clusterNode
+		subpath := ""
+		for _, level := range topology {
+			value := node.Labels[level.Label]
+			key := fmt.Sprintf("%s/%s=%s", subpath, level.Label, value)
+			domain, exists := domainLookup[key]
+			if !exists {
+				domain = fluxgraph.MakeDomain(level.Type, value, subpath, registry.Index(level.Type, key))
+				domainLookup[key] = domain
+
+				// This is one example of bidirectional, I won't document in
+				// all following occurrences but this is what the function does
+				// [cluster] -> contains -> [domain]
+				// [domain]  ->       in -> [cluster]
+				fluxgraph.MakeBidirectionalEdge(parent.Id, domain.Id)
+			}
+			parent = domain
+			subpath = strings.TrimPrefix(fmt.Sprintf("%s/%s", subpath, domain.Metadata.Name), "/")
 		}
 
-		// These are requests for existing pods, for cpu and memory
+		// TODO possibly look at pod resources vs. node.Status.Allocatable
+		// Make the compute node, which is a child of the lowest domain
+		// The parameters here are the node name, and the parent path
+		computeNode := fluxgraph.MakeNode(node.Name, subpath, registry.Index(jgf.NodeType, node.Name))
+
+		// [domain] -> contains -> [compute node]
+		fluxgraph.MakeBidirectionalEdge(parent.Id, computeNode.Id)
+
+		// Resources on the node are under the domains -> node
+		subpath = fmt.Sprintf("%s/%s", subpath, computeNode.Metadata.Name)
+		isAllocated := func(name string) func(int64) bool {
+			return func(index int64) bool {
+				return options.Allocated[fluxgraph.ContainmentPath(subpath, name, index)]
+			}
+		}
+
+		// These are requests for existing pods, for cpu and memory. Our own pods
+		// only count for what the allocations we know about do not cover, e.g.,
+		// all of them after a restart, when fluxion has no allocations to replay.
 		reqs := computeTotalRequests(pods)
+		covered := coveredRequests(&node, resources, coreUnit, memoryUnit, storageUnit, isAllocated)
+		addUncovered(reqs, computeTotalRequests(own), covered)
 		cpuReqs := reqs[corev1.ResourceCPU]
 		memReqs := reqs[corev1.ResourceMemory]
 
@@ -172,7 +250,7 @@ func CreateInClusterJGF(filename, skipLabel string) error {
 		totalMem := node.Status.Allocatable.Memory().Value()
 
 		// Values accounting for requests
-		availCpu := int64((totalCpu - cpuReqs.MilliValue()) / 1000)
+		availCpu := jgf.Vertices(totalCpu-cpuReqs.MilliValue(), coreUnit)
 		availMem := totalMem - memReqs.Value()
 
 		// Show existing to compare to
@@ -185,56 +263,265 @@ func CreateInClusterJGF(filename, skipLabel string) error {
 		// keep track of overall total
 		totalAllocCpu += availCpu
 		fmt.Printf("      available mem: %d\n", availMem)
-		gpuAllocatable, hasGpuAllocatable := node.Status.Allocatable["nvidia.com/gpu"]
-
-		// TODO possibly look at pod resources vs. node.Status.Allocatable
-		// Make the compute node, which is a child of the subnet
-		// The parameters here are the node name, and the parent path
-		computeNode := fluxgraph.MakeNode(node.Name, subnetNode.Metadata.Name, int64(nodeCount))
-
-		// [subnet] -> contains -> [compute node]
-		fluxgraph.MakeBidirectionalEdge(subnetNode.Id, computeNode.Id)
-
-		// Here we are adding GPU resources under nodes
-		if hasGpuAllocatable {
-			fmt.Println("GPU Resource quantity ", gpuAllocatable.Value())
-			for index := 0; index < int(gpuAllocatable.Value()); index++ {
 
-				// The subpath (from and not including root) is the subnet -> node
-				subpath := fmt.Sprintf("%s/%s", subnetNode.Metadata.Name, computeNode.Metadata.Name)
-
-				// TODO: can this size be greater than 1?
-				gpuNode := fluxgraph.MakeGPU(jgf.NvidiaGPU, subpath, 1, int64(index))
+		// A node that is cordoned or not ready keeps its vertices, but is not matched
+		if IsNodeDown(&node) {
+			fmt.Printf("Node %s is unschedulable or not ready, marking down\n", node.GetName())
+			err = fluxgraph.SetProperty(computeNode.Id, jgf.DownProperty, "true")
+			if err != nil {
+				return nil, err
+			}
+		}
 
-				// [compute] -> contains -> [gpu]
-				fluxgraph.MakeBidirectionalEdge(computeNode.Id, gpuNode.Id)
+		// Selected labels and taints can be required (or excluded) by a jobspec
+		properties := NodeProperties(&node, options.Labels)
+		keys := []string{}
+		for key := range properties {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			err = fluxgraph.SetProperty(computeNode.Id, key, properties[key])
+			if err != nil {
+				return nil, err
 			}
+		}
 
+		// Here we are adding extended resources (e.g., gpus) under nodes
+		for _, extended := range resources {
+			quantity, ok := node.Status.Allocatable[corev1.ResourceName(extended.Name)]
+			if !ok {
+				continue
+			}
+			used := reqs[corev1.ResourceName(extended.Name)]
+			total := quantity.Value() / extended.Unit
+			avail := (quantity.Value() - used.Value()) / extended.Unit
+			fmt.Printf("      available %s: %d\n", extended.Name, avail)
+			for _, index := range chooseIndices(total, avail, isAllocated(extended.Type)) {
+				resourceNode := fluxgraph.MakeResource(extended.Type, subpath, index)
+
+				// [compute] -> contains -> [resource]
+				fluxgraph.MakeBidirectionalEdge(computeNode.Id, resourceNode.Id)
+			}
 		}
 
 		// Here is where we are adding cores
-		for index := 0; index < int(availCpu); index++ {
-			subpath := fmt.Sprintf("%s/%s", subnetNode.Metadata.Name, computeNode.Metadata.Name)
-			coreNode := fluxgraph.MakeCore(jgf.CoreType, subpath, int64(index))
+		for _, index := range chooseIndices(jgf.Vertices(totalCpu, coreUnit), availCpu, isAllocated(jgf.CoreType)) {
+			coreNode := fluxgraph.MakeCore(jgf.CoreType, subpath, index)
 			fluxgraph.MakeBidirectionalEdge(computeNode.Id, coreNode.Id)
 		}
 
-		// Here is where we are adding memory
-		fractionMem := availMem >> 30
-		for i := 0; i < int(fractionMem); i++ {
-			subpath := fmt.Sprintf("%s/%s", subnetNode.Metadata.Name, computeNode.Metadata.Name)
-			memoryNode := fluxgraph.MakeMemory(jgf.MemoryType, subpath, 1<<10, int64(i))
+		// Here is where we are adding memory, in whole units
+		totalMemVertices := jgf.Vertices(totalMem, memoryUnit)
+		availMemVertices := jgf.Vertices(availMem, memoryUnit)
+		for _, index := range chooseIndices(totalMemVertices, availMemVertices, isAllocated(jgf.MemoryType)) {
+			memoryNode := fluxgraph.MakeMemory(jgf.MemoryType, subpath, jgf.PoolSize(memoryUnit), index)
 			fluxgraph.MakeBidirectionalEdge(computeNode.Id, memoryNode.Id)
 		}
+
+		// And ephemeral storage, also in whole units
+		storageReqs := reqs[corev1.ResourceEphemeralStorage]
+		totalStorage := node.Status.Allocatable.StorageEphemeral().Value()
+		totalStorageVertices := jgf.Vertices(totalStorage, storageUnit)
+		availStorageVertices := jgf.Vertices(totalStorage-storageReqs.Value(), storageUnit)
+		for _, index := range chooseIndices(totalStorageVertices, availStorageVertices, isAllocated(jgf.StorageType)) {
+			storageNode := fluxgraph.MakeStorage(subpath, jgf.PoolSize(storageUnit), index)
+			fluxgraph.MakeBidirectionalEdge(computeNode.Id, storageNode.Id)
+		}
 	}
 	fmt.Printf("\nCan request at most %d exclusive cpu", totalAllocCpu)
+	return &fluxgraph, nil
+}
 
-	// Get the jgf back as bytes, and we will return string
-	err = fluxgraph.WriteJGF(filename)
-	if err != nil {
-		return err
+// ParseTopology parses topology levels from the top down, as a comma separated
+// list of type=label (e.g., zone=topology.kubernetes.io/zone,rack=example.com/rack)
+func ParseTopology(topology string) ([]TopologyLevel, error) {
+	levels := []TopologyLevel{}
+	seen := map[string]bool{}
+	for _, item := range strings.Split(topology, ",") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("topology level %q must be type=label", item)
+		}
+		level := TopologyLevel{Type: strings.TrimSpace(parts[0]), Label: strings.TrimSpace(parts[1])}
+		for _, reserved := range reservedTypes {
+			if level.Type == reserved {
+				return nil, fmt.Errorf("topology level type %q is reserved", level.Type)
+			}
+		}
+		if seen[level.Type] {
+			return nil, fmt.Errorf("topology level type %q is used more than once", level.Type)
+		}
+		seen[level.Type] = true
+		levels = append(levels, level)
+	}
+	return levels, nil
+}
+
+// ParseExtendedResources parses a comma separated list of extended resources, each
+// as name=type:unit where the type and unit are optional. The default type is the
+// name without a domain (e.g., example.com/foo is foo) and the default unit is 1.
+// For example: nvidia.com/gpu=gpu,amd.com/gpu=amdgpu,hugepages-2Mi=hugepages:2Mi
+func ParseExtendedResources(resources string) ([]ExtendedResource, error) {
+	parsed := []ExtendedResource{}
+	seen := map[string]bool{}
+	for _, item := range strings.Split(resources, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, rest, _ := strings.Cut(item, "=")
+		resourceType, unit, _ := strings.Cut(rest, ":")
+		if resourceType == "" {
+			parts := strings.Split(name, "/")
+			resourceType = strings.ToLower(parts[len(parts)-1])
+		}
+		extended := ExtendedResource{Name: name, Type: resourceType, Unit: 1}
+		if unit != "" {
+			quantity, err := resource.ParseQuantity(unit)
+			if err != nil {
+				return nil, fmt.Errorf("extended resource %s has an invalid unit %q: %w", name, unit, err)
+			}
+			extended.Unit = quantity.Value()
+		}
+		if name == "" || extended.Unit <= 0 {
+			return nil, fmt.Errorf("extended resource %q must be name=type:unit with a positive unit", item)
+		}
+		for _, reserved := range reservedTypes {
+			if extended.Type == reserved && extended.Type != jgf.GPUType {
+				return nil, fmt.Errorf("extended resource type %q is reserved", extended.Type)
+			}
+		}
+		if seen[extended.Type] {
+			return nil, fmt.Errorf("extended resource type %q is used more than once", extended.Type)
+		}
+		seen[extended.Type] = true
+		parsed = append(parsed, extended)
+	}
+	return parsed, nil
+}
+
+// NodeProperties returns the properties for a node in the graph. Each of the
+// labels the node has is published as the key and as key=value, and each taint
+// as taint:key=value:effect.
+func NodeProperties(node *corev1.Node, labels []string) map[string]string {
+	properties := map[string]string{}
+	for _, key := range labels {
+		value, ok := node.Labels[key]
+		if !ok {
+			continue
+		}
+		properties[key] = value
+		properties[jgf.LabelProperty(key, value)] = "true"
+	}
+	for _, taint := range node.Spec.Taints {
+		properties[jgf.TaintProperty(taint.ToString())] = "true"
+	}
+	return properties
+}
+
+// chooseIndices returns which of total resources (by index) to put in the graph
+// when only count are available. Allocated ones are always kept, even if that is
+// more than count (the node is overcommitted by pods from another scheduler) so
+// the allocations stay valid. The rest are taken in order, so the same pods give
+// the same vertices, and a resource freed by another scheduler comes back with
+// the same vertex id.
+func chooseIndices(total, count int64, allocated func(int64) bool) []int64 {
+	chosen := map[int64]bool{}
+	for index := int64(0); index < total; index++ {
+		if allocated(index) {
+			chosen[index] = true
+		}
+	}
+	free := count - int64(len(chosen))
+	for index := int64(0); index < total && free > 0; index++ {
+		if !chosen[index] {
+			chosen[index] = true
+			free -= 1
+		}
+	}
+	indices := []int64{}
+	for index := int64(0); index < total; index++ {
+		if chosen[index] {
+			indices = append(indices, index)
+		}
+	}
+	return indices
+}
+
+// filterScheduler removes pods scheduled by a scheduler from the list
+func filterScheduler(podList *corev1.PodList, schedulerName string) *corev1.PodList {
+	filtered := &corev1.PodList{}
+	for _, pod := range podList.Items {
+		if pod.Spec.SchedulerName != schedulerName {
+			filtered.Items = append(filtered.Items, pod)
+		}
+	}
+	return filtered
+}
+
+// selectScheduler returns the pods of a scheduler
+func selectScheduler(podList *corev1.PodList, schedulerName string) *corev1.PodList {
+	selected := &corev1.PodList{}
+	for _, pod := range podList.Items {
+		if pod.Spec.SchedulerName == schedulerName {
+			selected.Items = append(selected.Items, pod)
+		}
+	}
+	return selected
+}
+
+// coveredRequests returns the resources of a node that are in allocations, which
+// hold (at least) what the pods they were made for request
+func coveredRequests(
+	node *corev1.Node,
+	resources []ExtendedResource,
+	coreUnit, memoryUnit, storageUnit int64,
+	isAllocated func(string) func(int64) bool,
+) map[corev1.ResourceName]resource.Quantity {
+	count := func(name string, total int64) int64 {
+		allocated := int64(0)
+		for index := int64(0); index < total; index++ {
+			if isAllocated(name)(index) {
+				allocated += 1
+			}
+		}
+		return allocated
+	}
+	cores := count(jgf.CoreType, jgf.Vertices(node.Status.Allocatable.Cpu().MilliValue(), coreUnit))
+	memory := count(jgf.MemoryType, jgf.Vertices(node.Status.Allocatable.Memory().Value(), memoryUnit))
+	storage := count(jgf.StorageType, jgf.Vertices(node.Status.Allocatable.StorageEphemeral().Value(), storageUnit))
+	covered := map[corev1.ResourceName]resource.Quantity{
+		corev1.ResourceCPU:              *resource.NewMilliQuantity(cores*coreUnit, resource.DecimalSI),
+		corev1.ResourceMemory:           *resource.NewQuantity(memory*memoryUnit, resource.BinarySI),
+		corev1.ResourceEphemeralStorage: *resource.NewQuantity(storage*storageUnit, resource.BinarySI),
+	}
+	for _, extended := range resources {
+		quantity, ok := node.Status.Allocatable[corev1.ResourceName(extended.Name)]
+		if ok {
+			allocated := count(extended.Type, quantity.Value()/extended.Unit)
+			covered[corev1.ResourceName(extended.Name)] = *resource.NewQuantity(allocated*extended.Unit, resource.DecimalSI)
+		}
+	}
+	return covered
+}
+
+// addUncovered adds the requests of our own pods that are more than what is
+// covered by allocations to the requests of a node
+func addUncovered(reqs, own, covered map[corev1.ResourceName]resource.Quantity) {
+	for name, quantity := range own {
+		uncovered := quantity.DeepCopy()
+		uncovered.Sub(covered[name])
+		if uncovered.Sign() <= 0 {
+			continue
+		}
+		total := reqs[name]
+		total.Add(uncovered)
+		reqs[name] = total
 	}
-	return nil
 }
 
 // computeTotalRequests sums up the pod requests for the list. We do not consider limits.
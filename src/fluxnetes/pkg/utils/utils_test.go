@@ -0,0 +1,421 @@
+package utils
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/converged-computing/fluxnetes/pkg/fluxion-grpc"
+	"github.com/converged-computing/fluxnetes/pkg/jgf"
+	"github.com/converged-computing/fluxnetes/pkg/jobspec"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// newNode returns a ready node with cpu and memory
+func newNode(name string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("2"),
+				corev1.ResourceMemory: resource.MustParse("2Gi"),
+			},
+			Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+			},
+		},
+	}
+}
+
+// vertexIds returns the vertex id for each containment path in the graph
+func vertexIds(graph *jgf.FluxJGF) map[string]string {
+	ids := map[string]string{}
+	for _, node := range graph.Graph.Nodes {
+		ids[node.Metadata.Paths["containment"]] = node.Id
+	}
+	return ids
+}
+
+func TestBuildJGFStableIds(t *testing.T) {
+	ctx := context.Background()
+	clientset := fake.NewSimpleClientset(newNode("node-b"), newNode("node-c"))
+	registry := jgf.NewRegistry()
+
+	graph, err := BuildJGF(ctx, clientset, GraphOptions{Registry: registry})
+	assert.Nil(t, err)
+
+	// cluster, subnet, and for each node: node, 2 cores, 2 memory
+	assert.Equal(t, 12, len(graph.Graph.Nodes))
+	before := vertexIds(graph)
+
+	// A node added (listed first) and one removed should not change the others
+	_, err = clientset.CoreV1().Nodes().Create(ctx, newNode("node-a"), metav1.CreateOptions{})
+	assert.Nil(t, err)
+	err = clientset.CoreV1().Nodes().Delete(ctx, "node-b", metav1.DeleteOptions{})
+	assert.Nil(t, err)
+
+	graph, err = BuildJGF(ctx, clientset, GraphOptions{Registry: registry})
+	assert.Nil(t, err)
+	assert.Nil(t, graph.Validate())
+	after := vertexIds(graph)
+	assert.Equal(t, 12, len(after))
+
+	for path, id := range before {
+		newId, ok := after[path]
+		if ok {
+			assert.Equal(t, id, newId, path)
+		}
+	}
+
+	// The new node does not reuse ids of the removed one
+	seen := map[string]bool{}
+	for _, id := range before {
+		seen[id] = true
+	}
+	for path, id := range after {
+		if _, ok := before[path]; !ok {
+			assert.False(t, seen[id], path)
+		}
+	}
+}
+
+func TestBuildJGFNodeDown(t *testing.T) {
+	ctx := context.Background()
+
+	cordoned := newNode("cordoned")
+	cordoned.Spec.Unschedulable = true
+	notReady := newNode("not-ready")
+	notReady.Status.Conditions[0].Status = corev1.ConditionFalse
+	controlPlane := newNode("control-plane")
+	controlPlane.Labels = map[string]string{controlPlaneLabel: ""}
+
+	clientset := fake.NewSimpleClientset(newNode("ready"), cordoned, notReady, controlPlane)
+	graph, err := BuildJGF(ctx, clientset, GraphOptions{})
+	assert.Nil(t, err)
+
+	down := map[string]bool{}
+	for _, node := range graph.Graph.Nodes {
+		if node.Metadata.Type != jgf.NodeType {
+			continue
+		}
+		down[node.Metadata.Basename] = node.Metadata.Properties[jgf.DownProperty] == "true"
+	}
+
+	// The control plane is not in the graph, and nodes that are down keep their vertices
+	assert.Equal(t, map[string]bool{"ready": false, "cordoned": true, "not-ready": true}, down)
+	assert.Equal(t, 1+1+3*5, len(graph.Graph.Nodes))
+}
+
+func TestBuildJGFOwnScheduler(t *testing.T) {
+	ctx := context.Background()
+
+	pod := func(name, scheduler string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: corev1.PodSpec{
+				NodeName:      "node",
+				SchedulerName: scheduler,
+				Containers: []corev1.Container{{
+					Name: "c",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+					},
+				}},
+			},
+		}
+	}
+	clientset := fake.NewSimpleClientset(newNode("node"), pod("other", "default-scheduler"), pod("ours", "fluxnetes"))
+
+	countCores := func(graph *jgf.FluxJGF) int {
+		count := 0
+		for _, node := range graph.Graph.Nodes {
+			if node.Metadata.Type == jgf.CoreType {
+				count += 1
+			}
+		}
+		return count
+	}
+
+	// The fake clientset ignores the field selector, so all pods are on the node
+	graph, err := BuildJGF(ctx, clientset, GraphOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, countCores(graph))
+
+	// After a restart there are no allocations for our pod, so it still counts
+	graph, err = BuildJGF(ctx, clientset, GraphOptions{OwnScheduler: "fluxnetes"})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, countCores(graph))
+
+	// When an allocation we know about covers it, the allocated core is kept
+	graph, err = BuildJGF(ctx, fake.NewSimpleClientset(newNode("node")), GraphOptions{})
+	assert.Nil(t, err)
+	path := ""
+	for _, node := range graph.Graph.Nodes {
+		if node.Metadata.Type == jgf.CoreType {
+			path = node.Metadata.Paths["containment"]
+		}
+	}
+	graph, err = BuildJGF(ctx, clientset, GraphOptions{OwnScheduler: "fluxnetes", Allocated: map[string]bool{path: true}})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, countCores(graph))
+	for _, node := range graph.Graph.Nodes {
+		if node.Metadata.Type == jgf.CoreType {
+			assert.Equal(t, path, node.Metadata.Paths["containment"])
+		}
+	}
+}
+
+func TestBuildJGFKeepsAllocated(t *testing.T) {
+	ctx := context.Background()
+	clientset := fake.NewSimpleClientset(newNode("node"))
+	registry := jgf.NewRegistry()
+
+	corePaths := func(graph *jgf.FluxJGF) []string {
+		paths := []string{}
+		for _, node := range graph.Graph.Nodes {
+			if node.Metadata.Type == jgf.CoreType {
+				paths = append(paths, node.Metadata.Paths["containment"])
+			}
+		}
+		return paths
+	}
+	graph, err := BuildJGF(ctx, clientset, GraphOptions{Registry: registry})
+	assert.Nil(t, err)
+	paths := corePaths(graph)
+	assert.Equal(t, 2, len(paths))
+	before := vertexIds(graph)
+
+	// Another scheduler takes one core, and the second core is allocated
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"},
+		Spec: corev1.PodSpec{
+			NodeName: "node",
+			Containers: []corev1.Container{{
+				Name: "c",
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+				},
+			}},
+		},
+	}
+	_, err = clientset.CoreV1().Pods("default").Create(ctx, pod, metav1.CreateOptions{})
+	assert.Nil(t, err)
+
+	options := GraphOptions{Registry: registry, Allocated: map[string]bool{paths[1]: true}}
+	graph, err = BuildJGF(ctx, clientset, options)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{paths[1]}, corePaths(graph))
+	assert.Equal(t, before[paths[1]], vertexIds(graph)[paths[1]])
+
+	// An allocation is kept when the node is overcommitted
+	pod.Name = "another"
+	_, err = clientset.CoreV1().Pods("default").Create(ctx, pod, metav1.CreateOptions{})
+	assert.Nil(t, err)
+	graph, err = BuildJGF(ctx, clientset, options)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{paths[1]}, corePaths(graph))
+
+	// When the pods are gone, both cores are back with the same ids
+	for _, name := range []string{"other", "another"} {
+		err = clientset.CoreV1().Pods("default").Delete(ctx, name, metav1.DeleteOptions{})
+		assert.Nil(t, err)
+	}
+	graph, err = BuildJGF(ctx, clientset, options)
+	assert.Nil(t, err)
+	assert.Equal(t, before, vertexIds(graph))
+}
+
+func TestNodeProperties(t *testing.T) {
+	node := newNode("node")
+	node.Labels = map[string]string{"kubernetes.io/arch": "amd64", "unpublished": "value"}
+	node.Spec.Taints = []corev1.Taint{{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule}}
+
+	expected := map[string]string{
+		"kubernetes.io/arch":             "amd64",
+		"kubernetes.io/arch=amd64":       "true",
+		"taint:dedicated=gpu:NoSchedule": "true",
+	}
+	assert.Equal(t, expected, NodeProperties(node, []string{"kubernetes.io/arch", "pool"}))
+}
+
+func TestBuildJGFTopology(t *testing.T) {
+	ctx := context.Background()
+	node := func(name, region, rack string) *corev1.Node {
+		n := newNode(name)
+		n.Labels = map[string]string{"region": region, "rack": rack}
+		return n
+	}
+
+	// The same rack name in two regions is two racks
+	clientset := fake.NewSimpleClientset(node("a", "east", "r1"), node("b", "east", "r1"), node("c", "west", "r1"))
+	topology, err := ParseTopology("region=region,rack=rack")
+	assert.Nil(t, err)
+	graph, err := BuildJGF(ctx, clientset, GraphOptions{Topology: topology})
+	assert.Nil(t, err)
+	assert.Nil(t, graph.Validate())
+	assert.Equal(t, 2, graph.CountType("region"))
+	assert.Equal(t, 2, graph.CountType("rack"))
+	assert.Equal(t, 0, graph.CountType(jgf.SubnetType))
+
+	paths := map[string]string{}
+	for _, vertex := range graph.Graph.Nodes {
+		if vertex.Metadata.Type == jgf.NodeType {
+			paths[vertex.Metadata.Basename] = vertex.Metadata.Paths["containment"]
+		}
+	}
+	assert.Equal(t, "/k8scluster0/east0/r10/a0", paths["a"])
+	assert.Equal(t, "/k8scluster0/east0/r10/b1", paths["b"])
+	assert.Equal(t, "/k8scluster0/west1/r11/c2", paths["c"])
+
+	_, err = ParseTopology("node=kubernetes.io/hostname")
+	assert.NotNil(t, err)
+	_, err = ParseTopology("zone")
+	assert.NotNil(t, err)
+}
+
+func TestBuildJGFExtendedResources(t *testing.T) {
+	ctx := context.Background()
+	node := newNode("node")
+	node.Status.Allocatable["nvidia.com/gpu"] = resource.MustParse("2")
+	node.Status.Allocatable["example.com/foo"] = resource.MustParse("3")
+	node.Status.Allocatable["hugepages-2Mi"] = resource.MustParse("8Mi")
+
+	resources, err := ParseExtendedResources("nvidia.com/gpu=gpu,example.com/foo,hugepages-2Mi=hugepages:2Mi")
+	assert.Nil(t, err)
+	assert.Equal(t, ExtendedResource{Name: "example.com/foo", Type: "foo", Unit: 1}, resources[1])
+	assert.Equal(t, int64(2*1024*1024), resources[2].Unit)
+
+	clientset := fake.NewSimpleClientset(node)
+	graph, err := BuildJGF(ctx, clientset, GraphOptions{Resources: resources})
+	assert.Nil(t, err)
+	assert.Nil(t, graph.Validate())
+	assert.Equal(t, 2, graph.CountType(jgf.GPUType))
+	assert.Equal(t, 3, graph.CountType("foo"))
+	assert.Equal(t, 4, graph.CountType("hugepages"))
+
+	_, err = ParseExtendedResources("example.com/core=core")
+	assert.NotNil(t, err)
+	_, err = ParseExtendedResources("hugepages-2Mi=hugepages:0")
+	assert.NotNil(t, err)
+}
+
+// poolRequest returns the free size (in MB) of the memory or storage vertices in
+// a graph, and the amount (in MB) a jobspec for one pod asks for. Each vertex is
+// checked to be a pool of the unit, and the request to be whole vertices.
+func poolRequest(t *testing.T, graph *jgf.FluxJGF, js *jobspec.JobSpec, resourceType string, unit int64) (int64, int64) {
+	free := int64(0)
+	for _, node := range graph.Graph.Nodes {
+		if node.Metadata.Type == resourceType {
+			assert.Equal(t, "MB", node.Metadata.Unit)
+			assert.Equal(t, unit>>20, node.Metadata.Size)
+			free += node.Metadata.Size
+		}
+	}
+	requested := int64(0)
+	for _, with := range js.Version.Resources[0].With {
+		if with.Type == resourceType {
+			requested = with.Count
+		}
+	}
+	assert.Zero(t, requested%(unit>>20))
+	return free, requested
+}
+
+func TestMemoryAccounting(t *testing.T) {
+	ctx := context.Background()
+
+	// request returns the free memory of a node and what a pod asks for (in MB)
+	request := func(free, request string, unit int64) (int64, int64) {
+		node := newNode("node")
+		node.Status.Allocatable[corev1.ResourceMemory] = resource.MustParse(free)
+		graph, err := BuildJGF(ctx, fake.NewSimpleClientset(node), GraphOptions{MemoryUnit: unit})
+		assert.Nil(t, err)
+
+		memory := resource.MustParse(request)
+		spec := &pb.PodSpec{Cpu: 1, Memory: memory.Value()}
+		bytes, err := jobspec.CreateJobSpecYaml(spec, 1, jobspec.Options{MemoryUnit: unit})
+		assert.Nil(t, err)
+		js := jobspec.JobSpec{}
+		assert.Nil(t, yaml.Unmarshal(bytes, &js))
+		if unit == 0 {
+			unit = jgf.DefaultMemoryUnit
+		}
+		return poolRequest(t, graph, &js, jgf.MemoryType, unit)
+	}
+
+	// A pod requesting 4Gi asks for 4096 MB, which is four 1Gi vertices
+	free, requested := request("4Gi", "4Gi", 0)
+	assert.Equal(t, int64(4096), free)
+	assert.Equal(t, int64(4096), requested)
+	free, requested = request("3584Mi", "4Gi", 0)
+	assert.Equal(t, int64(3072), free)
+	assert.Greater(t, requested, free)
+
+	// Requests are rounded up to a unit, and node memory down
+	_, requested = request("1Gi", "100Mi", 0)
+	assert.Equal(t, int64(1024), requested)
+	free, requested = request("4Gi", "4097Mi", 0)
+	assert.Equal(t, int64(5120), requested)
+	assert.Greater(t, requested, free)
+	free, requested = request("4097Mi", "4097Mi", 1<<20)
+	assert.Equal(t, free, requested)
+	free, requested = request("3584Mi", "3500Mi", 256<<20)
+	assert.Equal(t, int64(3584), free)
+	assert.Equal(t, int64(3584), requested)
+	free, requested = request("3500Mi", "3500Mi", 256<<20)
+	assert.Equal(t, int64(3328), free)
+	assert.Greater(t, requested, free)
+
+	// A unit that is not a whole number of Mi cannot be the size of a vertex
+	_, err := BuildJGF(ctx, fake.NewSimpleClientset(newNode("node")), GraphOptions{MemoryUnit: 1500000})
+	assert.NotNil(t, err)
+}
+
+func TestBuildJGFCoreUnit(t *testing.T) {
+	ctx := context.Background()
+	node := newNode("node")
+	node.Status.Allocatable[corev1.ResourceCPU] = resource.MustParse("3900m")
+
+	// A node with 3.9 free cores has 3 whole cores, or 39 of 100m
+	graph, err := BuildJGF(ctx, fake.NewSimpleClientset(node), GraphOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, graph.CountType(jgf.CoreType))
+	graph, err = BuildJGF(ctx, fake.NewSimpleClientset(node), GraphOptions{CoreUnit: 100})
+	assert.Nil(t, err)
+	assert.Equal(t, 39, graph.CountType(jgf.CoreType))
+}
+
+func TestStorageAccounting(t *testing.T) {
+	ctx := context.Background()
+	node := newNode("node")
+	node.Status.Allocatable[corev1.ResourceEphemeralStorage] = resource.MustParse("10Gi")
+	graph, err := BuildJGF(ctx, fake.NewSimpleClientset(node), GraphOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, 10, graph.CountType(jgf.StorageType))
+
+	// A pod requesting more than a whole unit needs another vertex, and asks for
+	// the size of three 1Gi vertices
+	storage := resource.MustParse("2500Mi")
+	bytes, err := jobspec.CreateJobSpecYaml(&pb.PodSpec{Cpu: 1, Storage: storage.Value()}, 1, jobspec.Options{})
+	assert.Nil(t, err)
+	js := jobspec.JobSpec{}
+	assert.Nil(t, yaml.Unmarshal(bytes, &js))
+	free, requested := poolRequest(t, graph, &js, jgf.StorageType, jgf.DefaultStorageUnit)
+	assert.Equal(t, int64(10240), free)
+	assert.Equal(t, int64(3072), requested)
+
+	// With a larger unit, the request is the size of one vertex
+	graph, err = BuildJGF(ctx, fake.NewSimpleClientset(node), GraphOptions{StorageUnit: 5 << 30})
+	assert.Nil(t, err)
+	bytes, err = jobspec.CreateJobSpecYaml(&pb.PodSpec{Cpu: 1, Storage: storage.Value()}, 1, jobspec.Options{StorageUnit: 5 << 30})
+	assert.Nil(t, err)
+	js = jobspec.JobSpec{}
+	assert.Nil(t, yaml.Unmarshal(bytes, &js))
+	free, requested = poolRequest(t, graph, &js, jgf.StorageType, 5<<30)
+	assert.Equal(t, int64(10240), free)
+	assert.Equal(t, int64(5120), requested)
+}